@@ -0,0 +1,396 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/cluster"
+	"github.com/aryannaik/curius-search/internal/curius"
+	"github.com/aryannaik/curius-search/internal/embeddings"
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/projection"
+	"github.com/aryannaik/curius-search/internal/savedsearch"
+	"github.com/aryannaik/curius-search/internal/snapshot"
+	"github.com/aryannaik/curius-search/internal/source"
+	"github.com/aryannaik/curius-search/internal/vectors"
+)
+
+// app holds the index and the data derived from it, loaded from the data
+// dir, for the subcommands to share.
+type app struct {
+	cfg         config
+	store       *index.Store
+	embedClient *embeddings.Client
+	clusters    *cluster.Service
+	maps        *projection.Service
+	saved       *savedsearch.Store
+	snapshots   *snapshot.Manager
+}
+
+// newApp loads the index, clusters, map and saved searches from cfg.DataDir
+// without writing to it, for subcommands that only read. Load errors are
+// logged; the app starts with whatever could be read.
+func newApp(cfg config) *app {
+	return openApp(cfg, false)
+}
+
+// newWritableApp is newApp for subcommands that change the index (serve,
+// index, import, snapshot restore). It recovers the write-ahead log and
+// assigns the entries of indexes built before multi-user support.
+func newWritableApp(cfg config) *app {
+	return openApp(cfg, true)
+}
+
+func openApp(cfg config, writable bool) *app {
+	a := &app{
+		cfg:         cfg,
+		store:       index.NewStore(cfg.DataDir),
+		embedClient: embeddings.NewClient(cfg.OllamaHost, cfg.EmbedModel),
+		clusters:    cluster.NewService(cfg.DataDir),
+		maps:        projection.NewService(cfg.DataDir),
+		saved:       savedsearch.NewStore(cfg.DataDir),
+		snapshots:   snapshot.NewManager(cfg.DataDir),
+	}
+
+	a.store.SetSemanticWeight(float32(cfg.SemanticWeight))
+	a.embedClient.SetTimeout(cfg.OllamaTimeout)
+
+	// Load existing index
+	load := a.store.LoadReadOnly
+	if writable {
+		load = a.store.LoadFromDisk
+	}
+	if err := load(); err != nil {
+		log.Printf("Warning: could not load existing index: %v", err)
+	}
+	if n := a.store.Recovered(); n > 0 && writable {
+		log.Printf("Replayed %d index changes from the write-ahead log", n)
+	}
+
+	if m := a.store.Model(); m != "" && m != cfg.EmbedModel && a.store.Count() > 0 {
+		log.Printf("Warning: index was embedded with %s but ollama.model is %s; run 'index -reindex' to re-embed", m, cfg.EmbedModel)
+	}
+
+	if err := a.clusters.LoadFromDisk(); err != nil {
+		log.Printf("Warning: could not load clusters: %v", err)
+	}
+	if err := a.maps.LoadFromDisk(); err != nil {
+		log.Printf("Warning: could not load map: %v", err)
+	}
+	if err := a.saved.LoadFromDisk(); err != nil {
+		log.Printf("Warning: could not load saved searches: %v", err)
+	}
+
+	// Indexes built before multi-user support belong to the first configured user
+	if writable && len(cfg.CuriusUserIDs) > 0 {
+		if n := a.store.ClaimUnowned(cfg.CuriusUserIDs[0]); n > 0 {
+			log.Printf("Assigned %d existing entries to user %s", n, cfg.CuriusUserIDs[0])
+		}
+	}
+
+	return a
+}
+
+// clear discards embeddings before a full re-index: everyone's when userID
+// is empty, otherwise one user's. The index is snapshotted first so the
+// re-index can be rolled back; if that fails nothing is cleared.
+func (a *app) clear(userID string) error {
+	if a.cfg.SnapshotBeforeReindex && a.store.Count() > 0 {
+		meta, err := a.snapshots.Create(a.store, "", "pre-reindex")
+		if err != nil {
+			return fmt.Errorf("snapshot before re-index: %w", err)
+		}
+		log.Printf("Saved snapshot %s (%d bookmarks)", meta.Name, meta.Count)
+		a.pruneSnapshots()
+	}
+
+	if userID == "" {
+		a.store.Clear()
+		log.Println("Cleared existing index for full re-index")
+	} else {
+		a.store.ClearUser(userID)
+		log.Printf("Cleared existing index for user %s", userID)
+	}
+	return nil
+}
+
+// pruneSnapshots applies the snapshot retention policy.
+func (a *app) pruneSnapshots() []snapshot.Meta {
+	removed, err := a.snapshots.Prune(a.retention())
+	if err != nil {
+		log.Printf("Error pruning snapshots: %v", err)
+	}
+	for _, meta := range removed {
+		log.Printf("Removed snapshot %s", meta.Name)
+	}
+	return removed
+}
+
+func (a *app) retention() snapshot.Retention {
+	return snapshot.Retention{Keep: a.cfg.SnapshotKeep, MaxAge: a.cfg.SnapshotMaxAge}
+}
+
+// runIndex syncs the given users, or every configured and network user when userIDs is empty.
+func (a *app) runIndex(userIDs []string) {
+	before := a.store.Version()
+
+	if len(userIDs) > 0 {
+		for _, userID := range userIDs {
+			a.syncUser(userID, a.store.UserState(userID).Network)
+		}
+	} else {
+		for _, userID := range a.cfg.CuriusUserIDs {
+			a.syncUser(userID, false)
+		}
+		for _, userID := range networkUsers(a.cfg) {
+			a.syncUser(userID, true)
+		}
+	}
+
+	if err := a.store.SaveToDisk(); err != nil {
+		log.Printf("Error saving index: %v", err)
+		return
+	}
+
+	log.Printf("Index saved: %d total entries", a.store.Count())
+
+	if a.store.Version() != before || len(a.clusters.Clusters()) == 0 {
+		a.rebuildDerived()
+	}
+}
+
+// rebuildDerived re-runs topic clustering and the 2D map over the whole index.
+func (a *app) rebuildDerived() {
+	entries := a.store.Entries()
+
+	log.Println("Clustering bookmarks...")
+	if err := a.clusters.Rebuild(entries, a.cfg.ClusterCount); err != nil {
+		log.Printf("Error saving clusters: %v", err)
+	} else {
+		log.Printf("Clustered into %d topics", len(a.clusters.Clusters()))
+	}
+
+	log.Println("Projecting bookmark map...")
+	if err := a.maps.Rebuild(entries); err != nil {
+		log.Printf("Error saving map: %v", err)
+	} else {
+		log.Printf("Map built: %d points", len(a.maps.Map().Points))
+	}
+}
+
+// networkUsers returns the users whose bookmarks form the network corpus:
+// the explicit CURIUS_NETWORK_USER_IDS list, or everyone the configured users
+// follow when CURIUS_NETWORK is enabled. Configured users are never included.
+func networkUsers(cfg config) []string {
+	own := make(map[string]bool, len(cfg.CuriusUserIDs))
+	for _, id := range cfg.CuriusUserIDs {
+		own[id] = true
+	}
+
+	candidates := cfg.CuriusNetworkUserIDs
+	if len(candidates) == 0 && cfg.CuriusNetwork {
+		for _, userID := range cfg.CuriusUserIDs {
+			following, err := curius.NewClient(userID).FetchFollowing()
+			if err != nil {
+				log.Printf("Error fetching users followed by %s: %v", userID, err)
+				continue
+			}
+			for _, u := range following {
+				candidates = append(candidates, strconv.Itoa(u.ID))
+			}
+		}
+	}
+
+	var users []string
+	seen := make(map[string]bool)
+	for _, id := range candidates {
+		if own[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		users = append(users, id)
+	}
+	return users
+}
+
+// syncUser fetches one user's bookmarks and embeds those not yet in the index.
+// Network users are followed accounts whose bookmarks are indexed for discovery.
+func (a *app) syncUser(userID string, network bool) {
+	state := a.store.UserState(userID)
+	state.Network = network
+
+	log.Printf("Fetching bookmarks from Curius for user %s...", userID)
+	links, err := source.NewCurius(userID).Links()
+	if err != nil {
+		log.Printf("Error fetching bookmarks for user %s: %v", userID, err)
+		state.LastError = err.Error()
+		a.store.SetUserState(userID, state)
+		return
+	}
+	log.Printf("Fetched %d bookmarks", len(links))
+
+	a.indexLinks(links, userID, network)
+
+	a.store.SetUserState(userID, index.UserState{
+		LastSyncAt: time.Now(),
+		LinkCount:  len(links),
+		Network:    network,
+	})
+}
+
+// runImport indexes the bookmarks in an export file on behalf of userID.
+func (a *app) runImport(path, format, userID string) error {
+	src, err := source.Open(path, format)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Importing bookmarks from %s...", path)
+	links, err := src.Links()
+	if err != nil {
+		return fmt.Errorf("import %s: %w", path, err)
+	}
+	log.Printf("Read %d %s bookmarks", len(links), src.Name())
+
+	a.indexLinks(links, userID, false)
+
+	if err := a.store.SaveToDisk(); err != nil {
+		return fmt.Errorf("save index: %w", err)
+	}
+
+	log.Printf("Index saved: %d total entries", a.store.Count())
+
+	a.rebuildDerived()
+	return nil
+}
+
+// runImportVectors validates imported embeddings against the index and the
+// configured model, then adds the bookmarks they describe and keeps the rest
+// for the next sync to use instead of embedding. flagModel is the model
+// given on the command line, for files that do not name one.
+func (a *app) runImportVectors(vecs []vectors.Vector, flagModel string) error {
+	if len(vecs) == 0 {
+		return errors.New("no vectors to import")
+	}
+
+	model := flagModel
+	for _, v := range vecs {
+		switch {
+		case v.Model == "":
+		case model == "":
+			model = v.Model
+		case v.Model != model:
+			return fmt.Errorf("%s was embedded with %s, not %s", v.Key, v.Model, model)
+		}
+	}
+	if model == "" {
+		return errors.New("vectors do not name their model; pass -model")
+	}
+	if model != a.cfg.EmbedModel {
+		return fmt.Errorf("vectors were embedded with %s but ollama.model is %s", model, a.cfg.EmbedModel)
+	}
+	if m := a.store.Model(); m != "" && m != model && a.store.Count() > 0 {
+		return fmt.Errorf("vectors were embedded with %s but the index with %s", model, m)
+	}
+	if err := vectors.Validate(vecs, a.store.Dim()); err != nil {
+		return err
+	}
+
+	a.store.SetModel(model)
+	added, kept, skipped := 0, 0, 0
+	for _, v := range vecs {
+		switch {
+		case a.store.HasKey(v.Key):
+			skipped++
+		case v.Entry != nil:
+			a.store.Add(*v.Entry)
+			added++
+		default:
+			a.store.AddPrecomputed(v.Key, index.PrecomputedVector{UserID: v.UserID, Model: model, Embedding: v.Embedding})
+			kept++
+		}
+	}
+
+	if err := a.store.SaveToDisk(); err != nil {
+		return fmt.Errorf("save index: %w", err)
+	}
+	log.Printf("Imported %d bookmarks with their embeddings; kept %d embeddings for the next sync; skipped %d already indexed", added, kept, skipped)
+
+	if added > 0 {
+		a.rebuildDerived()
+	}
+	return nil
+}
+
+// indexLinks embeds the links not yet in the index and adds them under userID.
+func (a *app) indexLinks(links []source.Link, userID string, network bool) {
+	// Find new bookmarks to embed
+	var toEmbed []source.Link
+	for _, link := range links {
+		if !a.store.Has(link.Source, userID, link.ID) {
+			toEmbed = append(toEmbed, link)
+		}
+	}
+
+	if len(toEmbed) == 0 {
+		log.Printf("Index is up to date for user %s, no new bookmarks to embed", userID)
+		return
+	}
+
+	log.Printf("Embedding %d new bookmarks for user %s...", len(toEmbed), userID)
+	a.store.SetModel(a.embedClient.Model())
+
+	// Checkpoint progress so a crash or restart resumes where it stopped
+	unsaved, lastCheckpoint := 0, time.Now()
+	imported := 0
+
+	for i, link := range toEmbed {
+		// Embeddings imported with import-vectors save a round trip
+		vec, ok := a.store.Precomputed(index.EntryKey(link.Source, userID, link.ID), a.embedClient.Model())
+		if ok {
+			imported++
+		} else {
+			var err error
+			vec, err = a.embedClient.Embed(index.BuildEmbeddingText(link))
+			if err != nil {
+				log.Printf("Error embedding bookmark %d (%s): %v", link.ID, link.Title, err)
+				continue
+			}
+		}
+
+		entry := index.IndexEntry{
+			ID:          link.ID,
+			Source:      link.Source,
+			UserID:      userID,
+			Network:     network,
+			Title:       link.Title,
+			URL:         link.URL,
+			Highlights:  link.Highlights,
+			Tags:        link.Tags,
+			Description: link.Description,
+			CreatedAt:   link.CreatedAt,
+			IndexedAt:   time.Now(),
+			Embedding:   vec,
+		}
+
+		a.store.Add(entry)
+
+		unsaved++
+		if unsaved >= a.cfg.CheckpointEvery || time.Since(lastCheckpoint) >= a.cfg.CheckpointInterval {
+			if err := a.store.Checkpoint(); err != nil {
+				log.Printf("Error checkpointing index: %v", err)
+			}
+			unsaved, lastCheckpoint = 0, time.Now()
+		}
+
+		if (i+1)%10 == 0 || i+1 == len(toEmbed) {
+			log.Printf("  Embedded %d/%d", i+1, len(toEmbed))
+		}
+	}
+	if imported > 0 {
+		log.Printf("Used %d imported embeddings for user %s", imported, userID)
+	}
+}
@@ -0,0 +1,680 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aryannaik/curius-search/internal/dedupe"
+	"github.com/aryannaik/curius-search/internal/export"
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/search"
+	"github.com/aryannaik/curius-search/internal/snapshot"
+	"github.com/aryannaik/curius-search/internal/stats"
+	"github.com/aryannaik/curius-search/internal/tagsuggest"
+	"github.com/aryannaik/curius-search/internal/textutil"
+	"github.com/aryannaik/curius-search/internal/tui"
+	"github.com/aryannaik/curius-search/internal/vectors"
+)
+
+// cmdIndex syncs the index from Curius and exits.
+func cmdIndex(args []string) error {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	reindexFlag := fs.Bool("reindex", false, "Force full re-index (discard existing embeddings)")
+	userFlag := fs.String("user", "", "Only sync this Curius user (with -reindex, only their embeddings are discarded)")
+	fs.Parse(args)
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.requireUsers(); err != nil {
+		return err
+	}
+	a := newWritableApp(cfg)
+
+	if *reindexFlag {
+		if err := a.clear(*userFlag); err != nil {
+			return err
+		}
+	}
+
+	var userIDs []string
+	if *userFlag != "" {
+		userIDs = []string{*userFlag}
+	}
+	a.runIndex(userIDs)
+	return nil
+}
+
+// cmdImport indexes a bookmark export file or Curius dump and exits.
+func cmdImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	formatFlag := fs.String("format", "auto", "Import file format: curius, netscape, csv, json or jsonl (auto detects from extension)")
+	userFlag := fs.String("user", "", "User ID to own imported bookmarks (default: first CURIUS_USER_ID)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: curius-search import [flags] PATH")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	userID := *userFlag
+	if userID == "" {
+		if err := cfg.requireUsers(); err != nil {
+			return fmt.Errorf("%w (or pass -user)", err)
+		}
+		userID = cfg.CuriusUserIDs[0]
+	}
+
+	return newWritableApp(cfg).runImport(fs.Arg(0), *formatFlag, userID)
+}
+
+// cmdImportVectors imports precomputed embeddings, so bookmarks embedded
+// elsewhere are not embedded again.
+func cmdImportVectors(args []string) error {
+	fs := flag.NewFlagSet("import-vectors", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	formatFlag := fs.String("format", "auto", "Vectors format: jsonl or npy (auto detects from extension)")
+	idsFlag := fs.String("ids", "", "Row ID file for npy (default: the .npy file with .ids.txt)")
+	modelFlag := fs.String("model", "", "Model the vectors were embedded with (required for npy and JSONL without a model field)")
+	userFlag := fs.String("user", "", "User ID for bookmarks given by bare ID (default: first CURIUS_USER_ID)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: curius-search import-vectors [flags] FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	format := *formatFlag
+	if format == "auto" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+		if format == "json" {
+			format = "jsonl"
+		}
+	}
+	if format != "jsonl" && format != "npy" {
+		return fmt.Errorf("invalid vectors format %q (want jsonl or npy)", format)
+	}
+	if format == "npy" && *modelFlag == "" {
+		return errors.New("npy files carry no model; pass -model")
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	userID := *userFlag
+	if userID == "" && len(cfg.CuriusUserIDs) > 0 {
+		userID = cfg.CuriusUserIDs[0]
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var vecs []vectors.Vector
+	if format == "npy" {
+		idsPath := *idsFlag
+		if idsPath == "" {
+			idsPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".ids.txt"
+		}
+		ids, err := os.Open(idsPath)
+		if err != nil {
+			return fmt.Errorf("open ID file: %w (pass -ids)", err)
+		}
+		defer ids.Close()
+		vecs, err = vectors.ReadNPY(f, ids, userID, *modelFlag)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		vecs, err = vectors.ReadJSONL(f, userID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return newWritableApp(cfg).runImportVectors(vecs, *modelFlag)
+}
+
+// outputFlag registers the -format flag shared by commands that print results.
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "table", "Output format: table, json or urls")
+}
+
+// cmdSearch runs a query against the local index and prints the results.
+func cmdSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	limitFlag := fs.Int("limit", 10, "Maximum number of results")
+	userFlag := fs.String("user", "", "Only search this user's bookmarks")
+	sourceFlag := fs.String("source", "", "Only search bookmarks from this source")
+	networkFlag := fs.String("network", "include", "Network bookmarks: include, exclude or only")
+	formatFlag := outputFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: curius-search search [flags] QUERY...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	network, err := index.ParseNetworkMode(*networkFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	a := newApp(cfg)
+	results, err := a.searcher().Search(query, *limitFlag, index.Filter{
+		UserID:  *userFlag,
+		Source:  *sourceFlag,
+		Network: network,
+	})
+	if err != nil {
+		return err
+	}
+	return printResults(os.Stdout, results, *formatFlag)
+}
+
+// cmdSimilar prints the bookmarks most similar to a bookmark, given by ID
+// or by key (source/user/id).
+func cmdSimilar(args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	limitFlag := fs.Int("limit", 10, "Maximum number of results")
+	userFlag := fs.String("user", "", "User whose bookmark the ID is, if several share it")
+	sourceFlag := fs.String("source", "", "Source of the bookmark the ID is, if several share it")
+	formatFlag := outputFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: curius-search similar [flags] ID|KEY")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	a := newApp(cfg)
+
+	key := fs.Arg(0)
+	if id, err := strconv.Atoi(key); err == nil {
+		entry, err := a.store.Lookup(*sourceFlag, *userFlag, id)
+		if err != nil {
+			return err
+		}
+		key = entry.Key()
+	}
+	results, err := a.searcher().FindSimilar(key, *limitFlag, index.Filter{})
+	if err != nil {
+		return err
+	}
+	return printResults(os.Stdout, results, *formatFlag)
+}
+
+// cmdTUI opens the interactive terminal search interface.
+func cmdTUI(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	userFlag := fs.String("user", "", "Only search this user's bookmarks")
+	sourceFlag := fs.String("source", "", "Only search bookmarks from this source")
+	networkFlag := fs.String("network", "include", "Network bookmarks: include, exclude or only")
+	fs.Parse(args)
+
+	network, err := index.ParseNetworkMode(*networkFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	a := newApp(cfg)
+	return tui.Run(a.searcher(), index.Filter{
+		UserID:  *userFlag,
+		Source:  *sourceFlag,
+		Network: network,
+	}, os.Stdin, os.Stdout)
+}
+
+// cmdConfig prints the effective configuration and where each value came from.
+func cmdConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Usage: curius-search config print [-format table|toml] [-config FILE] [-set key=value]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	formatFlag := fs.String("format", "table", "Output format: table or toml")
+	fs.Parse(args[1:])
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+
+	switch *formatFlag {
+	case "toml":
+		cfg.writeTOML(os.Stdout)
+	case "table":
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUE\tFROM")
+		for _, s := range settings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", s.key, orNone(cfg.formatValue(s, false)), cfg.origins[s.key])
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", *formatFlag)
+	}
+	return nil
+}
+
+const snapshotUsage = `Usage: curius-search snapshot <command> [flags]
+
+Commands:
+  list              List snapshots, newest first
+  create [-name N]  Snapshot the index
+  restore NAME      Replace the index with a snapshot (stop the server first)
+  delete NAME       Remove a snapshot
+  prune             Remove snapshots beyond snapshots.keep or older than snapshots.max_age
+`
+
+// cmdSnapshot lists, creates, restores and prunes index snapshots.
+func cmdSnapshot(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, snapshotUsage)
+		os.Exit(2)
+	}
+	sub := args[0]
+
+	fs := flag.NewFlagSet("snapshot "+sub, flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	var nameFlag *string
+	var backupFlag *bool
+	switch sub {
+	case "create":
+		nameFlag = fs.String("name", "", "Snapshot name (default: the current time)")
+	case "restore":
+		backupFlag = fs.Bool("backup", true, "Snapshot the current index before replacing it")
+	}
+	fs.Parse(args[1:])
+
+	name := fs.Arg(0)
+	if (sub == "restore" || sub == "delete") && name == "" {
+		fmt.Fprintf(os.Stderr, "Usage: curius-search snapshot %s NAME\n", sub)
+		os.Exit(2)
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		metas, err := snapshot.NewManager(cfg.DataDir).List()
+		if err != nil {
+			return err
+		}
+		if len(metas) == 0 {
+			fmt.Println("No snapshots")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tCREATED\tBOOKMARKS\tMODEL\tSIZE\tREASON")
+		for _, m := range metas {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%.1f MB\t%s\n", m.Name, m.CreatedAt.Format("2006-01-02 15:04"), m.Count, orNone(m.Model), float64(m.Size)/(1<<20), orNone(m.Reason))
+		}
+		return tw.Flush()
+
+	case "create":
+		a := newApp(cfg)
+		meta, err := a.snapshots.Create(a.store, *nameFlag, "manual")
+		if err != nil {
+			return err
+		}
+		log.Printf("Saved snapshot %s (%d bookmarks)", meta.Name, meta.Count)
+		a.pruneSnapshots()
+
+	case "restore":
+		a := newWritableApp(cfg)
+		if _, err := a.snapshots.Get(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if *backupFlag && a.store.Count() > 0 {
+			meta, err := a.snapshots.Create(a.store, "", "pre-restore")
+			if err != nil {
+				return fmt.Errorf("snapshot before restore: %w", err)
+			}
+			log.Printf("Saved snapshot %s (%d bookmarks)", meta.Name, meta.Count)
+		}
+		meta, err := a.snapshots.Restore(a.store, name)
+		if err != nil {
+			return err
+		}
+		log.Printf("Restored snapshot %s: %d bookmarks", meta.Name, a.store.Count())
+		if meta.Model != "" && meta.Model != cfg.EmbedModel {
+			log.Printf("Warning: snapshot was embedded with %s but ollama.model is %s", meta.Model, cfg.EmbedModel)
+		}
+		a.rebuildDerived()
+
+	case "delete":
+		if err := snapshot.NewManager(cfg.DataDir).Delete(name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		log.Printf("Removed snapshot %s", name)
+
+	case "prune":
+		a := &app{cfg: cfg, snapshots: snapshot.NewManager(cfg.DataDir)}
+		if removed := a.pruneSnapshots(); len(removed) == 0 {
+			log.Println("No snapshots to remove")
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown snapshot command %q\n\n%s", sub, snapshotUsage)
+		os.Exit(2)
+	}
+	return nil
+}
+
+// searcher builds a searcher over the loaded index. Duplicate groups are
+// not computed up front, so CLI results are not collapsed by embedding
+// similarity, only by canonical URL.
+func (a *app) searcher() *search.Searcher {
+	return search.NewSearcher(a.store, a.embedClient, dedupe.NewFinder(a.store), a.clusters)
+}
+
+// printResults writes results as an aligned table, a JSON array, or one
+// URL per line for piping into other tools.
+func printResults(w io.Writer, results []search.Result, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if results == nil {
+			results = []search.Result{}
+		}
+		return enc.Encode(results)
+	case "urls":
+		bw := bufio.NewWriter(w)
+		for _, r := range results {
+			fmt.Fprintln(bw, r.URL)
+		}
+		return bw.Flush()
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSCORE\tDATE\tTITLE\tURL")
+		for _, r := range results {
+			// Operator-only queries list bookmarks without scoring them
+			score := "-"
+			if r.Score != 0 {
+				score = fmt.Sprintf("%.1f%%", r.Score*100)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", r.ID, score, r.CreatedAt, textutil.Truncate(r.Title, 60), r.URL)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q (want table, json or urls)", format)
+}
+
+// cmdStats prints library analytics for the local index.
+func cmdStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	formatFlag := fs.String("format", "table", "Output format: table or json")
+	fs.Parse(args)
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	a := newApp(cfg)
+	st := stats.Compute(a.store.Entries())
+
+	switch *formatFlag {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			stats.Stats
+			Model     string `json:"embeddingModel"`
+			DiskBytes int64  `json:"indexSizeBytes"`
+		}{st, a.store.Model(), a.store.DiskSize()})
+	case "table":
+	default:
+		return fmt.Errorf("unknown format %q (want table or json)", *formatFlag)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Bookmarks\t%d\n", st.Total)
+	fmt.Fprintf(w, "Embedding model\t%s (%d dims)\n", orNone(a.store.Model()), st.Dim)
+	fmt.Fprintf(w, "Index size\t%.1f MB\n", float64(a.store.DiskSize())/(1<<20))
+	fmt.Fprintf(w, "Highlights\t%d on %d bookmarks\n", st.Highlights.Total, st.Highlights.Entries)
+	fmt.Fprintf(w, "Missing\ttags %.1f%%, highlights %.1f%%, description %.1f%%\n", st.Missing.Tags, st.Missing.Highlights, st.Missing.Description)
+	w.Flush()
+
+	printCounts("Top domains", st.Domains)
+	printCounts("Top tags", st.Tags)
+
+	if len(st.Timeline) > 0 {
+		fmt.Println("\nSaves per month")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, m := range st.Timeline {
+			fmt.Fprintf(w, "  %s\t%d\n", m.Month, m.Count)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func printCounts(title string, counts []stats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %s\t%d\n", c.Name, c.Count)
+	}
+	w.Flush()
+}
+
+// cmdExport writes the index as JSONL, CSV, Markdown or an .npy matrix.
+func cmdExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	formatFlag := fs.String("format", "auto", "Export format: jsonl, csv, markdown or npy (auto detects from the -o extension)")
+	vectorsFlag := fs.Bool("vectors", false, "Include embedding vectors (jsonl)")
+	outFlag := fs.String("o", "", "Output file (default: stdout; required for npy)")
+	idsFlag := fs.String("ids", "", "Row ID sidecar for npy (default: the -o file with .ids.txt)")
+	userFlag := fs.String("user", "", "Only export this user's bookmarks")
+	sourceFlag := fs.String("source", "", "Only export bookmarks from this source")
+	tagFlag := fs.String("tag", "", "Only export bookmarks with this tag")
+	networkFlag := fs.String("network", "include", "Network bookmarks: include, exclude or only")
+	fs.Parse(args)
+
+	format := *formatFlag
+	if format == "auto" {
+		format = strings.TrimPrefix(filepath.Ext(*outFlag), ".")
+	}
+	format, err := export.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	if format == export.FormatNPY && *outFlag == "" {
+		return errors.New("npy export needs an output file (-o vectors.npy)")
+	}
+	network, err := index.ParseNetworkMode(*networkFlag)
+	if err != nil {
+		return err
+	}
+	filter := index.Filter{
+		UserID:  *userFlag,
+		Source:  *sourceFlag,
+		Tag:     *tagFlag,
+		Network: network,
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	a := newApp(cfg)
+
+	if format == export.FormatNPY {
+		idsPath := *idsFlag
+		if idsPath == "" {
+			idsPath = strings.TrimSuffix(*outFlag, filepath.Ext(*outFlag)) + ".ids.txt"
+		}
+		m := export.NewMatrix(a.store, filter)
+		if err := writeFile(*outFlag, m.WriteNPY); err != nil {
+			return err
+		}
+		if err := writeFile(idsPath, m.WriteIDs); err != nil {
+			return err
+		}
+		log.Printf("Exported %d x %d matrix to %s and row IDs to %s", len(m.Keys), m.Dim, *outFlag, idsPath)
+		if m.Skipped > 0 {
+			log.Printf("Skipped %d bookmarks without a %d-dim embedding", m.Skipped, m.Dim)
+		}
+		return nil
+	}
+
+	var n int
+	write := func(w io.Writer) error {
+		switch format {
+		case export.FormatCSV:
+			n, err = export.WriteCSV(w, a.store, filter)
+		case export.FormatMarkdown:
+			n, err = export.WriteMarkdown(w, a.store, filter)
+		default:
+			n, err = export.WriteJSONL(w, a.store, filter, *vectorsFlag)
+		}
+		return err
+	}
+	if *outFlag == "" {
+		err = write(os.Stdout)
+	} else {
+		err = writeFile(*outFlag, write)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d bookmarks", n)
+	return nil
+}
+
+// writeFile creates path and writes it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// cmdSuggestTags prints suggested tags for untagged bookmarks.
+func cmdSuggestTags(args []string) error {
+	fs := flag.NewFlagSet("suggest-tags", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	minFlag := fs.Float64("min", 0.3, "Minimum confidence")
+	fs.Parse(args)
+
+	if *minFlag < 0 || *minFlag > 1 {
+		return errors.New("-min must be between 0 and 1")
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	printTagSuggestions(newApp(cfg).store, float32(*minFlag))
+	return nil
+}
+
+// printTagSuggestions writes a report of suggested tags for every untagged bookmark.
+func printTagSuggestions(store *index.Store, minConfidence float32) {
+	suggester := tagsuggest.NewSuggester(store)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tSUGGESTED TAGS")
+
+	untagged, suggested := 0, 0
+	for _, entry := range store.Entries() {
+		if len(entry.Tags) > 0 {
+			continue
+		}
+		untagged++
+
+		var parts []string
+		for _, s := range suggester.Suggest(entry, 3) {
+			if s.Confidence >= minConfidence {
+				parts = append(parts, fmt.Sprintf("%s (%.2f)", s.Tag, s.Confidence))
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		suggested++
+
+		fmt.Fprintf(w, "%d\t%s\t%s\n", entry.ID, textutil.Truncate(entry.Title, 60), strings.Join(parts, ", "))
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d untagged bookmarks, %d with suggestions\n", untagged, suggested)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/scheduler"
+)
+
+// defaultConfigFile is read from the working directory when present and
+// no other file is named.
+const defaultConfigFile = "curius-search.toml"
+
+type config struct {
+	CuriusUserIDs         []string
+	CuriusNetwork         bool
+	CuriusNetworkUserIDs  []string
+	OllamaHost            string
+	EmbedModel            string
+	OllamaTimeout         time.Duration
+	Port                  string
+	DataDir               string
+	StaticDir             string
+	PublicURL             string
+	SemanticWeight        float64
+	IndexSchedule         string
+	IndexJitter           time.Duration
+	CheckpointEvery       int
+	CheckpointInterval    time.Duration
+	ClusterCount          int
+	LinkCheck             bool
+	LinkCheckInterval     time.Duration
+	LinkCheckHostDelay    time.Duration
+	LinkCheckWorkers      int
+	SnapshotBeforeReindex bool
+	SnapshotKeep          int
+	SnapshotMaxAge        time.Duration
+
+	// origins records where each setting's value came from, by key.
+	origins map[string]string
+}
+
+func defaultConfig() config {
+	return config{
+		OllamaHost:            "http://localhost:11434",
+		EmbedModel:            "nomic-embed-text",
+		OllamaTimeout:         120 * time.Second,
+		Port:                  "8990",
+		DataDir:               "data",
+		StaticDir:             "static",
+		SemanticWeight:        index.DefaultSemanticWeight,
+		IndexSchedule:         "24h",
+		CheckpointEvery:       50,
+		CheckpointInterval:    30 * time.Second,
+		LinkCheckInterval:     7 * 24 * time.Hour,
+		LinkCheckHostDelay:    2 * time.Second,
+		LinkCheckWorkers:      8,
+		SnapshotBeforeReindex: true,
+		SnapshotKeep:          10,
+	}
+}
+
+// setting describes one configuration value: its key in the config file
+// ("section.name"), its environment variable and the config field it sets.
+type setting struct {
+	key   string
+	env   string
+	doc   string
+	field func(c *config) any
+}
+
+// settings is the config schema, in the order config print shows it.
+var settings = []setting{
+	{"curius.users", "CURIUS_USER_ID", "Curius user IDs to index", func(c *config) any { return &c.CuriusUserIDs }},
+	{"curius.network", "CURIUS_NETWORK", "Also index the bookmarks of followed users", func(c *config) any { return &c.CuriusNetwork }},
+	{"curius.network_users", "CURIUS_NETWORK_USER_IDS", "User IDs to index as the network instead of the follow list", func(c *config) any { return &c.CuriusNetworkUserIDs }},
+	{"ollama.host", "OLLAMA_HOST", "Ollama API endpoint", func(c *config) any { return &c.OllamaHost }},
+	{"ollama.model", "EMBED_MODEL", "Embedding model", func(c *config) any { return &c.EmbedModel }},
+	{"ollama.timeout", "OLLAMA_TIMEOUT", "Timeout of each embedding request", func(c *config) any { return &c.OllamaTimeout }},
+	{"server.port", "PORT", "HTTP port", func(c *config) any { return &c.Port }},
+	{"server.static_dir", "STATIC_DIR", "Directory of the web frontend", func(c *config) any { return &c.StaticDir }},
+	{"server.public_url", "PUBLIC_URL", "Public base URL for feed and OpenSearch links (default: the request's host)", func(c *config) any { return &c.PublicURL }},
+	{"data_dir", "DATA_DIR", "Directory for the index and derived data", func(c *config) any { return &c.DataDir }},
+	{"search.semantic_weight", "SEARCH_SEMANTIC_WEIGHT", "Share of the hybrid score from cosine similarity (0-1)", func(c *config) any { return &c.SemanticWeight }},
+	{"index.schedule", "INDEX_SCHEDULE", "When serve re-syncs from Curius: an interval, a cron expression or off", func(c *config) any { return &c.IndexSchedule }},
+	{"index.jitter", "INDEX_JITTER", "Random delay of up to this long added to each scheduled sync", func(c *config) any { return &c.IndexJitter }},
+	{"index.checkpoint_every", "INDEX_CHECKPOINT_EVERY", "Log newly embedded bookmarks to disk after this many", func(c *config) any { return &c.CheckpointEvery }},
+	{"index.checkpoint_interval", "INDEX_CHECKPOINT_INTERVAL", "Log newly embedded bookmarks to disk at least this often", func(c *config) any { return &c.CheckpointInterval }},
+	{"clusters.count", "CLUSTER_COUNT", "Number of topic clusters (0 picks sqrt(n/2))", func(c *config) any { return &c.ClusterCount }},
+	{"linkcheck.enabled", "LINKCHECK_ENABLED", "Periodically check bookmarked URLs", func(c *config) any { return &c.LinkCheck }},
+	{"linkcheck.interval", "LINKCHECK_INTERVAL", "How long a link check result stays fresh", func(c *config) any { return &c.LinkCheckInterval }},
+	{"linkcheck.host_delay", "LINKCHECK_HOST_DELAY", "Minimum delay between requests to one host", func(c *config) any { return &c.LinkCheckHostDelay }},
+	{"linkcheck.workers", "LINKCHECK_WORKERS", "Concurrent link checks", func(c *config) any { return &c.LinkCheckWorkers }},
+	{"snapshots.before_reindex", "SNAPSHOT_BEFORE_REINDEX", "Snapshot the index before a re-index discards it", func(c *config) any { return &c.SnapshotBeforeReindex }},
+	{"snapshots.keep", "SNAPSHOT_KEEP", "Number of newest snapshots to keep (0 keeps all)", func(c *config) any { return &c.SnapshotKeep }},
+	{"snapshots.max_age", "SNAPSHOT_MAX_AGE", "Remove snapshots older than this (0 keeps them)", func(c *config) any { return &c.SnapshotMaxAge }},
+}
+
+func lookupSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// configFlags are the flags every command accepts to locate the config
+// file and override single settings.
+type configFlags struct {
+	path string
+	sets []string
+}
+
+// addConfigFlags registers -config and -set on fs.
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	cf := &configFlags{}
+	fs.StringVar(&cf.path, "config", "", "Config file (default $CURIUS_CONFIG, else ./"+defaultConfigFile+" if present)")
+	fs.Func("set", "Override a setting, as key=value (repeatable; see 'config print')", func(v string) error {
+		cf.sets = append(cf.sets, v)
+		return nil
+	})
+	return cf
+}
+
+// load builds the effective config: defaults, then the config file, then
+// environment variables (including .env), then -set flags.
+func (cf *configFlags) load() (config, error) {
+	_ = godotenv.Load()
+
+	cfg := defaultConfig()
+	cfg.origins = make(map[string]string, len(settings))
+	for _, s := range settings {
+		cfg.origins[s.key] = "default"
+	}
+
+	path, required := cf.path, true
+	if path == "" {
+		path = os.Getenv("CURIUS_CONFIG")
+	}
+	if path == "" {
+		path, required = defaultConfigFile, false
+	}
+	if err := cfg.applyFile(path, required); err != nil {
+		return config{}, err
+	}
+
+	for _, s := range settings {
+		v, ok := os.LookupEnv(s.env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := cfg.apply(s, v, "env "+s.env); err != nil {
+			return config{}, err
+		}
+	}
+
+	for _, kv := range cf.sets {
+		key, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return config{}, fmt.Errorf("-set %q: want key=value", kv)
+		}
+		s, ok := lookupSetting(strings.TrimSpace(key))
+		if !ok {
+			return config{}, fmt.Errorf("-set %q: unknown setting %q", kv, key)
+		}
+		if err := cfg.apply(s, v, "flag -set"); err != nil {
+			return config{}, err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return config{}, err
+	}
+	return cfg, nil
+}
+
+// applyFile layers the settings of a TOML config file. A missing file is
+// only an error if it was asked for.
+func (c *config) applyFile(path string, required bool) error {
+	var raw map[string]any
+	if _, err := toml.DecodeFile(path, &raw); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	flatten("", raw, values)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s, ok := lookupSetting(key)
+		if !ok {
+			return fmt.Errorf("config file %s: unknown setting %q", path, key)
+		}
+		if err := c.apply(s, tomlString(values[key]), "file "+path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatten turns nested TOML tables into dotted keys.
+func flatten(prefix string, m map[string]any, out map[string]any) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if table, ok := v.(map[string]any); ok {
+			flatten(k, table, out)
+			continue
+		}
+		out[k] = v
+	}
+}
+
+// tomlString renders a decoded TOML value in the form environment variables
+// use, so both go through the same parsing. Arrays become comma lists.
+func tomlString(v any) string {
+	if list, ok := v.([]any); ok {
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// apply parses v into the setting's field and records its origin.
+func (c *config) apply(s setting, v, origin string) error {
+	v = strings.TrimSpace(v)
+	var err error
+	switch p := s.field(c).(type) {
+	case *string:
+		*p = v
+	case *[]string:
+		*p = splitList(v)
+	case *bool:
+		*p, err = strconv.ParseBool(v)
+	case *int:
+		*p, err = strconv.Atoi(v)
+	case *float64:
+		*p, err = strconv.ParseFloat(v, 64)
+	case *time.Duration:
+		*p, err = time.ParseDuration(v)
+	}
+	if err != nil {
+		// strconv errors repeat the value; keep only the reason
+		if inner := errors.Unwrap(err); inner != nil {
+			err = inner
+		}
+		return fmt.Errorf("%s: invalid value %q (from %s): %w", s.key, v, origin, err)
+	}
+	c.origins[s.key] = origin
+	return nil
+}
+
+// validate checks the effective config, reporting every problem at once.
+func (c config) validate() error {
+	var errs []error
+	bad := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s (from %s)", key, fmt.Sprintf(format, args...), c.origins[key]))
+	}
+
+	for _, id := range append(append([]string{}, c.CuriusUserIDs...), c.CuriusNetworkUserIDs...) {
+		if _, err := strconv.Atoi(id); err != nil {
+			key := "curius.users"
+			if !contains(c.CuriusUserIDs, id) {
+				key = "curius.network_users"
+			}
+			bad(key, "user ID %q is not numeric", id)
+		}
+	}
+	if u, err := url.Parse(c.OllamaHost); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		bad("ollama.host", "%q is not an http(s) URL", c.OllamaHost)
+	}
+	if c.EmbedModel == "" {
+		bad("ollama.model", "must not be empty")
+	}
+	if c.OllamaTimeout <= 0 {
+		bad("ollama.timeout", "must be positive")
+	}
+	if p, err := strconv.Atoi(c.Port); err != nil || p < 1 || p > 65535 {
+		bad("server.port", "%q is not a port number", c.Port)
+	}
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			bad("server.public_url", "%q is not an http(s) URL", c.PublicURL)
+		}
+	}
+	if c.DataDir == "" {
+		bad("data_dir", "must not be empty")
+	}
+	if c.SemanticWeight < 0 || c.SemanticWeight > 1 {
+		bad("search.semantic_weight", "%g is not between 0 and 1", c.SemanticWeight)
+	}
+	if _, err := scheduler.Parse(c.IndexSchedule); err != nil {
+		bad("index.schedule", "%v", err)
+	}
+	if c.IndexJitter < 0 {
+		bad("index.jitter", "must not be negative")
+	}
+	if c.CheckpointEvery < 1 {
+		bad("index.checkpoint_every", "must be at least 1")
+	}
+	if c.CheckpointInterval <= 0 {
+		bad("index.checkpoint_interval", "must be positive")
+	}
+	if c.ClusterCount < 0 {
+		bad("clusters.count", "must not be negative")
+	}
+	if c.LinkCheckInterval <= 0 {
+		bad("linkcheck.interval", "must be positive")
+	}
+	if c.LinkCheckHostDelay < 0 {
+		bad("linkcheck.host_delay", "must not be negative")
+	}
+	if c.LinkCheckWorkers < 1 {
+		bad("linkcheck.workers", "must be at least 1")
+	}
+	if c.SnapshotKeep < 0 {
+		bad("snapshots.keep", "must not be negative")
+	}
+	if c.SnapshotMaxAge < 0 {
+		bad("snapshots.max_age", "must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n  %w", joinLines(errs))
+	}
+	return nil
+}
+
+// joinLines joins errors one per line, indented under validate's heading.
+func joinLines(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n  "))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// errNoUsers is returned by commands that sync from Curius when no user is configured.
+var errNoUsers = errors.New("no Curius user configured: set curius.users in the config file or CURIUS_USER_ID")
+
+// requireUsers checks that at least one Curius user is configured.
+func (c config) requireUsers() error {
+	if len(c.CuriusUserIDs) == 0 {
+		return errNoUsers
+	}
+	return nil
+}
+
+// splitList parses a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// writeTOML writes the effective config as a commented TOML file that
+// can be used as a starting point.
+func (c *config) writeTOML(w io.Writer) {
+	// Top-level keys must come before the first table
+	var top []setting
+	sections := make(map[string][]setting)
+	var order []string
+	for _, s := range settings {
+		section, _, ok := strings.Cut(s.key, ".")
+		if !ok {
+			top = append(top, s)
+			continue
+		}
+		if _, seen := sections[section]; !seen {
+			order = append(order, section)
+		}
+		sections[section] = append(sections[section], s)
+	}
+
+	write := func(s setting) {
+		name := s.key[strings.LastIndex(s.key, ".")+1:]
+		fmt.Fprintf(w, "# %s (%s)\n%s = %s\n", s.doc, s.env, name, c.formatValue(s, true))
+	}
+	for _, s := range top {
+		write(s)
+	}
+	for _, section := range order {
+		fmt.Fprintf(w, "\n[%s]\n", section)
+		for _, s := range sections[section] {
+			write(s)
+		}
+	}
+}
+
+// formatValue renders a setting's current value, as TOML when asTOML is set.
+func (c *config) formatValue(s setting, asTOML bool) string {
+	switch p := s.field(c).(type) {
+	case *string:
+		if asTOML {
+			return strconv.Quote(*p)
+		}
+		return *p
+	case *[]string:
+		if !asTOML {
+			return strings.Join(*p, ",")
+		}
+		quoted := make([]string, len(*p))
+		for i, item := range *p {
+			quoted[i] = strconv.Quote(item)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case *time.Duration:
+		if asTOML {
+			return strconv.Quote(p.String())
+		}
+		return p.String()
+	case *float64:
+		return strconv.FormatFloat(*p, 'g', -1, 64)
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *int:
+		return strconv.Itoa(*p)
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aryannaik/curius-search/internal/curius"
+)
+
+// check is one doctor diagnostic.
+type check struct {
+	name string
+	run  func() (string, error)
+}
+
+// cmdDoctor checks the configuration, data dir, index, Ollama and Curius,
+// printing one line per check. It fails if any check fails.
+func cmdDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	offlineFlag := fs.Bool("offline", false, "Skip the Curius API check")
+	fs.Parse(args)
+
+	cfg, err := cf.load()
+	if err != nil {
+		fmt.Printf("FAIL  %-10s %v\n", "config", err)
+		return errors.New("invalid config")
+	}
+	a := newApp(cfg)
+	dim := 0
+
+	checks := []check{
+		{"config", func() (string, error) {
+			if err := cfg.requireUsers(); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("users %s", strings.Join(cfg.CuriusUserIDs, ", ")), nil
+		}},
+		{"data dir", func() (string, error) {
+			if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
+				return "", err
+			}
+			probe := filepath.Join(cfg.DataDir, ".doctor")
+			if err := os.WriteFile(probe, nil, 0644); err != nil {
+				return "", fmt.Errorf("not writable: %w", err)
+			}
+			os.Remove(probe)
+			return cfg.DataDir, nil
+		}},
+		{"index", func() (string, error) {
+			dims := make(map[int]int)
+			for _, e := range a.store.Entries() {
+				dims[len(e.Embedding)]++
+			}
+			if len(dims) > 1 {
+				return "", fmt.Errorf("%d entries with mixed embedding sizes %v; run index -reindex", a.store.Count(), dims)
+			}
+			for d := range dims {
+				dim = d
+			}
+			return fmt.Sprintf("%d entries, model %s, %d dims", a.store.Count(), orNone(a.store.Model()), dim), nil
+		}},
+		{"ollama", func() (string, error) {
+			models, err := a.embedClient.Models()
+			if err != nil {
+				return "", fmt.Errorf("%s unreachable: %w", cfg.OllamaHost, err)
+			}
+			for _, m := range models {
+				if m == cfg.EmbedModel || strings.TrimSuffix(m, ":latest") == cfg.EmbedModel {
+					return fmt.Sprintf("%s has %s", cfg.OllamaHost, m), nil
+				}
+			}
+			return "", fmt.Errorf("model %s not installed; run: ollama pull %s", cfg.EmbedModel, cfg.EmbedModel)
+		}},
+		{"embedding", func() (string, error) {
+			vec, err := a.embedClient.Embed("curius-search doctor")
+			if err != nil {
+				return "", err
+			}
+			if m := a.store.Model(); m != "" && m != cfg.EmbedModel {
+				return "", fmt.Errorf("ollama.model is %s but the index was built with %s; run index -reindex", cfg.EmbedModel, m)
+			}
+			if dim != 0 && len(vec) != dim {
+				return "", fmt.Errorf("model returns %d dims but the index has %d; run index -reindex", len(vec), dim)
+			}
+			return fmt.Sprintf("%d dims", len(vec)), nil
+		}},
+	}
+	if !*offlineFlag {
+		checks = append(checks, check{"curius", func() (string, error) {
+			if len(cfg.CuriusUserIDs) == 0 {
+				return "", errNoUsers
+			}
+			if _, err := curius.NewClient(cfg.CuriusUserIDs[0]).FetchFollowing(); err != nil {
+				return "", err
+			}
+			return "API reachable", nil
+		}})
+	}
+
+	failed := 0
+	for _, c := range checks {
+		detail, err := c.run()
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %-10s %v\n", c.name, err)
+			continue
+		}
+		fmt.Printf("ok    %-10s %s\n", c.name, detail)
+	}
+
+	if failed > 0 {
+		return errors.New(plural(failed, "check") + " failed")
+	}
+	return nil
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
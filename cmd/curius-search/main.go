@@ -1,187 +1,74 @@
 package main
 
 import (
-	"context"
-	"flag"
+	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"github.com/joho/godotenv"
-
-	"github.com/aryannaik/curius-search/internal/curius"
-	"github.com/aryannaik/curius-search/internal/embeddings"
-	"github.com/aryannaik/curius-search/internal/index"
-	"github.com/aryannaik/curius-search/internal/server"
+	"strings"
 )
 
-type config struct {
-	CuriusUserID string
-	OllamaHost   string
-	EmbedModel   string
-	Port         string
-	DataDir      string
-	StaticDir    string
+// commands maps each subcommand to its entry point.
+var commands = map[string]func(args []string) error{
+	"serve":          cmdServe,
+	"index":          cmdIndex,
+	"import":         cmdImport,
+	"import-vectors": cmdImportVectors,
+	"search":         cmdSearch,
+	"similar":        cmdSimilar,
+	"tui":            cmdTUI,
+	"stats":          cmdStats,
+	"export":         cmdExport,
+	"suggest-tags":   cmdSuggestTags,
+	"doctor":         cmdDoctor,
+	"config":         cmdConfig,
+	"snapshot":       cmdSnapshot,
 }
 
-func loadConfig() config {
-	_ = godotenv.Load()
-
-	cfg := config{
-		CuriusUserID: os.Getenv("CURIUS_USER_ID"),
-		OllamaHost:   envOrDefault("OLLAMA_HOST", "http://localhost:11434"),
-		EmbedModel:   envOrDefault("EMBED_MODEL", "nomic-embed-text"),
-		Port:         envOrDefault("PORT", "8990"),
-		DataDir:      envOrDefault("DATA_DIR", "data"),
-		StaticDir:    envOrDefault("STATIC_DIR", "static"),
-	}
-
-	if cfg.CuriusUserID == "" {
-		log.Fatal("CURIUS_USER_ID is required. Set it in .env or as an environment variable.")
-	}
-
-	return cfg
-}
-
-func envOrDefault(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return def
-}
+const usage = `Usage: curius-search <command> [flags]
+
+Commands:
+  serve           Serve the web UI and API, syncing the index in the background (default)
+  index           Sync the index from Curius and exit
+  import          Index a bookmark export file or Curius API dump
+  import-vectors  Import precomputed embeddings (JSONL or .npy) to skip re-embedding
+  search          Search the local index and print the results
+  similar         Print the bookmarks most similar to a bookmark ID
+  tui             Search the local index interactively in the terminal
+  stats           Print library analytics
+  export          Export the index as JSONL, CSV, Markdown or an .npy matrix
+  suggest-tags    Print suggested tags for untagged bookmarks
+  doctor          Check configuration, Ollama and the index
+  config print    Show the effective configuration and where each value came from
+  snapshot        List, create, restore and prune index snapshots
+
+Every command accepts -config FILE and -set key=value.
+Run 'curius-search <command> -h' for the flags of a command.
+`
 
 func main() {
-	reindexFlag := flag.Bool("reindex", false, "Force full re-index (discard existing embeddings)")
-	indexOnlyFlag := flag.Bool("index-only", false, "Build index and exit (don't start server)")
-	flag.Parse()
-
-	cfg := loadConfig()
-
-	embedClient := embeddings.NewClient(cfg.OllamaHost, cfg.EmbedModel)
-	store := index.NewStore(cfg.DataDir)
-
-	// Load existing index
-	if err := store.LoadFromDisk(); err != nil {
-		log.Printf("Warning: could not load existing index: %v", err)
-	}
-
-	if *reindexFlag {
-		store.Clear()
-		log.Println("Cleared existing index for full re-index")
-	}
-
-	// Run indexing
-	runIndex(cfg, store, embedClient)
-
-	if *indexOnlyFlag {
-		log.Println("Index-only mode: exiting")
-		return
-	}
-
-	// Start server
-	reindexFn := func() {
-		log.Println("Re-index triggered")
-		runIndex(cfg, store, embedClient)
-	}
-
-	srv := server.New(cfg.Port, cfg.StaticDir, store, embedClient, reindexFn)
-
-	// Graceful shutdown
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Printf("Server stopped: %v", err)
-		}
-	}()
-
-	// Background periodic re-index every 24h
-	ticker := time.NewTicker(24 * time.Hour)
-	go func() {
-		for range ticker.C {
-			log.Println("Periodic re-index starting")
-			runIndex(cfg, store, embedClient)
-		}
-	}()
-
-	<-done
-	ticker.Stop()
-	log.Println("Shutting down...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
-	}
-
-	log.Println("Goodbye")
-}
-
-func runIndex(cfg config, store *index.Store, embedClient *embeddings.Client) {
-	curiusClient := curius.NewClient(cfg.CuriusUserID)
-
-	log.Println("Fetching bookmarks from Curius...")
-	links, err := curiusClient.FetchAllLinks()
-	if err != nil {
-		log.Printf("Error fetching bookmarks: %v", err)
+	args := os.Args[1:]
+
+	// No command, or only flags, runs the server
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		fmt.Print(usage)
 		return
 	}
-	log.Printf("Fetched %d bookmarks", len(links))
-
-	// Find new bookmarks to embed
-	var toEmbed []curius.Link
-	for _, link := range links {
-		if !store.Has(link.ID) {
-			toEmbed = append(toEmbed, link)
-		}
-	}
 
-	if len(toEmbed) == 0 {
-		log.Println("Index is up to date, no new bookmarks to embed")
+	if name == "help" {
+		fmt.Print(usage)
 		return
 	}
 
-	log.Printf("Embedding %d new bookmarks...", len(toEmbed))
-
-	for i, link := range toEmbed {
-		text := index.BuildEmbeddingText(link)
-		vec, err := embedClient.Embed(text)
-		if err != nil {
-			log.Printf("Error embedding bookmark %d (%s): %v", link.ID, link.Title, err)
-			continue
-		}
-
-		tags := make([]string, len(link.Tags))
-		for j, t := range link.Tags {
-			tags[j] = t.Name
-		}
-
-		entry := index.IndexEntry{
-			ID:          link.ID,
-			Title:       link.Title,
-			URL:         link.URL,
-			Highlights:  link.Highlights,
-			Tags:        tags,
-			Description: link.Description,
-			CreatedAt:   link.CreatedAt,
-			Embedding:   vec,
-		}
-
-		store.Add(entry)
-
-		if (i+1)%10 == 0 || i+1 == len(toEmbed) {
-			log.Printf("  Embedded %d/%d", i+1, len(toEmbed))
-		}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
 	}
 
-	if err := store.SaveToDisk(); err != nil {
-		log.Printf("Error saving index: %v", err)
-		return
+	if err := cmd(args); err != nil {
+		log.Fatalf("%s: %v", name, err)
 	}
-
-	log.Printf("Index saved: %d total entries", store.Count())
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/linkcheck"
+	"github.com/aryannaik/curius-search/internal/scheduler"
+	"github.com/aryannaik/curius-search/internal/server"
+)
+
+// cmdServe serves the web UI and API until interrupted, syncing the index
+// in the background.
+func cmdServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	reindexFlag := fs.Bool("reindex", false, "Force full re-index (discard existing embeddings)")
+	reindexUserFlag := fs.String("reindex-user", "", "Discard and re-index a single Curius user's embeddings")
+	fs.Parse(args)
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.requireUsers(); err != nil {
+		return err
+	}
+	a := newWritableApp(cfg)
+
+	// The schedule was validated with the config
+	schedule, _ := scheduler.Parse(cfg.IndexSchedule)
+	sched := scheduler.New(schedule, cfg.IndexJitter, func() {
+		log.Println("Periodic re-index starting")
+		a.runIndex(nil)
+	})
+
+	reindexFn := func(userID string, full bool) bool {
+		return sched.Go("manual", func() {
+			var userIDs []string
+			if userID != "" {
+				userIDs = []string{userID}
+			}
+			log.Printf("Re-index triggered (user: %q, full: %v)", userID, full)
+			if full {
+				if err := a.clear(userID); err != nil {
+					log.Printf("Re-index aborted: %v", err)
+					return
+				}
+			}
+			a.runIndex(userIDs)
+		})
+	}
+
+	srv := server.New(cfg.Port, cfg.StaticDir, a.store, a.embedClient, a.clusters, a.maps, a.saved, sched, reindexFn, cfg.CuriusUserIDs, cfg.PublicURL)
+
+	// Graceful shutdown
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil {
+			log.Printf("Server stopped: %v", err)
+		}
+	}()
+
+	// Sync in the background; until it finishes the server answers from
+	// the index on disk and reports that indexing is in progress
+	sched.Go("startup", func() {
+		var err error
+		if *reindexFlag {
+			err = a.clear("")
+		} else if *reindexUserFlag != "" {
+			err = a.clear(*reindexUserFlag)
+		}
+		if err != nil {
+			log.Printf("Re-index aborted, syncing new bookmarks only: %v", err)
+		}
+		a.runIndex(nil)
+	})
+
+	// Background jobs: dead-link checking and scheduled re-index
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.LinkCheck {
+		checker := linkcheck.NewChecker(a.store, linkcheck.Options{
+			HostDelay: cfg.LinkCheckHostDelay,
+			MaxAge:    cfg.LinkCheckInterval,
+			Workers:   cfg.LinkCheckWorkers,
+		})
+		// Poll hourly; each pass only re-checks links older than the interval
+		checker.Start(jobCtx, time.Hour)
+	}
+
+	sched.Start(jobCtx)
+
+	<-done
+	stopJobs()
+	log.Println("Shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+
+	log.Println("Goodbye")
+	return nil
+}
@@ -2,4 +2,10 @@ module github.com/aryannaik/curius-search
 
 go 1.22
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/term v0.21.0
+)
+
+require golang.org/x/sys v0.21.0 // indirect
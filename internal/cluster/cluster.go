@@ -0,0 +1,246 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+const (
+	maxIterations = 50
+	labelTerms    = 3
+	labelTags     = 3
+)
+
+// Cluster is a topic group of bookmarks.
+type Cluster struct {
+	ID    int      `json:"id"`
+	Label string   `json:"label"`
+	Terms []string `json:"terms"`
+	Tags  []string `json:"tags"`
+	Size  int      `json:"size"`
+}
+
+// Assignments is the persisted result of a clustering run.
+type Assignments struct {
+	Clusters []Cluster `json:"clusters"`
+	// Entries maps an index entry key to its cluster ID.
+	Entries   map[string]int `json:"entries"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// Service clusters the library after each index run and serves the
+// persisted assignments.
+type Service struct {
+	mu   sync.RWMutex
+	data Assignments
+	path string
+}
+
+func NewService(dataDir string) *Service {
+	return &Service{
+		data: Assignments{Entries: make(map[string]int)},
+		path: filepath.Join(dataDir, "clusters.json"),
+	}
+}
+
+// LoadFromDisk loads the last clustering run. Returns nil if there is none.
+func (s *Service) LoadFromDisk() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read clusters file: %w", err)
+	}
+
+	var a Assignments
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Errorf("decode clusters: %w", err)
+	}
+	if a.Entries == nil {
+		a.Entries = make(map[string]int)
+	}
+
+	s.mu.Lock()
+	s.data = a
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Service) saveToDisk(a Assignments) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("marshal clusters: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("write clusters file: %w", err)
+	}
+	return nil
+}
+
+// Rebuild clusters entries into k topics (k <= 0 picks sqrt(n/2)), labels
+// them, and persists the result.
+func (s *Service) Rebuild(entries []index.IndexEntry, k int) error {
+	a := Build(entries, k)
+	if err := s.saveToDisk(a); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.data = a
+	s.mu.Unlock()
+	return nil
+}
+
+// Clusters returns all clusters, largest first.
+func (s *Service) Clusters() []Cluster {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Cluster(nil), s.data.Clusters...)
+}
+
+// Get returns the cluster with the given ID.
+func (s *Service) Get(id int) (Cluster, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.data.Clusters {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Cluster{}, false
+}
+
+// ClusterOf returns the cluster ID of an entry key, or 0 if unassigned.
+func (s *Service) ClusterOf(key string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.Entries[key]
+}
+
+// Keys returns the entry keys assigned to cluster id.
+func (s *Service) Keys(id int) map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make(map[string]bool)
+	for k, c := range s.data.Entries {
+		if c == id {
+			keys[k] = true
+		}
+	}
+	return keys
+}
+
+// CreatedAt returns when the clusters were last rebuilt.
+func (s *Service) CreatedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.CreatedAt
+}
+
+// Build clusters entries with spherical k-means. Entries whose embedding
+// size differs from the majority (e.g. after a model switch) are left out.
+func Build(entries []index.IndexEntry, k int) Assignments {
+	a := Assignments{Entries: make(map[string]int), CreatedAt: time.Now()}
+
+	var vecs [][]float32
+	kept := entries[:0:0]
+	for _, e := range index.WithCommonDim(entries) {
+		if v := index.Normalize(e.Embedding); v != nil {
+			vecs = append(vecs, v)
+			kept = append(kept, e)
+		}
+	}
+	entries = kept
+	if len(entries) < 2 {
+		return a
+	}
+
+	if k <= 0 {
+		k = int(math.Round(math.Sqrt(float64(len(entries)) / 2)))
+	}
+	k = max(2, min(k, len(entries)))
+
+	assign, _ := kmeans(vecs, k, maxIterations)
+
+	members := make(map[int][]int)
+	for i, c := range assign {
+		members[c] = append(members[c], i)
+	}
+
+	docTerms := make([]map[string]bool, len(entries))
+	corpusDF := make(map[string]int)
+	for i, e := range entries {
+		docTerms[i] = terms(e)
+		for t := range docTerms[i] {
+			corpusDF[t]++
+		}
+	}
+
+	// Number clusters from 1, largest first
+	order := make([]int, 0, len(members))
+	for c := range members {
+		order = append(order, c)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if len(members[order[i]]) != len(members[order[j]]) {
+			return len(members[order[i]]) > len(members[order[j]])
+		}
+		return order[i] < order[j]
+	})
+
+	for n, c := range order {
+		id := n + 1
+		m := members[c]
+		cl := Cluster{
+			ID:    id,
+			Terms: distinctiveTerms(m, docTerms, corpusDF, labelTerms),
+			Tags:  commonTags(entries, m, labelTags),
+			Size:  len(m),
+		}
+		cl.Label = label(cl)
+		a.Clusters = append(a.Clusters, cl)
+		for _, i := range m {
+			a.Entries[entries[i].Key()] = id
+		}
+	}
+
+	return a
+}
+
+// label prefers the most common tags, topped up with distinctive terms.
+func label(c Cluster) string {
+	parts := append([]string(nil), c.Tags...)
+	for _, t := range c.Terms {
+		if len(parts) >= labelTerms {
+			break
+		}
+		dup := false
+		for _, p := range parts {
+			if strings.EqualFold(p, t) {
+				dup = true
+			}
+		}
+		if !dup {
+			parts = append(parts, t)
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("Cluster %d", c.ID)
+	}
+	return strings.Join(parts, ", ")
+}
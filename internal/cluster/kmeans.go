@@ -0,0 +1,131 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// kmeans runs spherical k-means (cosine distance) over unit vectors and
+// returns each vector's cluster and the cluster centroids. Initial centroids
+// are chosen with k-means++ from a fixed seed, so runs are reproducible.
+func kmeans(vecs [][]float32, k, maxIter int) ([]int, [][]float32) {
+	n := len(vecs)
+	if n == 0 || k <= 0 {
+		return nil, nil
+	}
+	if k > n {
+		k = n
+	}
+	dim := len(vecs[0])
+	rng := rand.New(rand.NewSource(1))
+
+	// k-means++ seeding: pick each next centroid with probability
+	// proportional to its distance from the nearest chosen one.
+	centroids := make([][]float32, 0, k)
+	centroids = append(centroids, clone(vecs[rng.Intn(n)]))
+	dist := make([]float64, n)
+	for len(centroids) < k {
+		var total float64
+		last := centroids[len(centroids)-1]
+		for i, v := range vecs {
+			d := 1 - float64(index.Dot(v, last))
+			if len(centroids) == 1 || d < dist[i] {
+				dist[i] = d
+			}
+			total += dist[i]
+		}
+		if total == 0 {
+			break
+		}
+		target := rng.Float64() * total
+		pick := n - 1
+		for i, d := range dist {
+			target -= d
+			if target <= 0 {
+				pick = i
+				break
+			}
+		}
+		centroids = append(centroids, clone(vecs[pick]))
+	}
+	k = len(centroids)
+
+	assign := make([]int, n)
+	for i := range assign {
+		assign[i] = -1
+	}
+
+	for iter := 0; iter < maxIter; iter++ {
+		changed := assignNearest(vecs, centroids, assign)
+		if changed == 0 {
+			break
+		}
+
+		sums := make([][]float64, k)
+		for c := range sums {
+			sums[c] = make([]float64, dim)
+		}
+		counts := make([]int, k)
+		for i, v := range vecs {
+			c := assign[i]
+			counts[c]++
+			for d, x := range v {
+				sums[c][d] += float64(x)
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				// Re-seed an empty cluster with a random point
+				centroids[c] = clone(vecs[rng.Intn(n)])
+				continue
+			}
+			for d := range centroids[c] {
+				centroids[c][d] = float32(sums[c][d])
+			}
+			index.NormalizeInPlace(centroids[c])
+		}
+	}
+
+	return assign, centroids
+}
+
+// assignNearest moves each vector to its most similar centroid and returns
+// how many assignments changed. Work is split across CPUs.
+func assignNearest(vecs, centroids [][]float32, assign []int) int {
+	workers := runtime.NumCPU()
+	changed := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(vecs); i += workers {
+				best, bestSim := 0, float32(math.Inf(-1))
+				for c, centroid := range centroids {
+					if sim := index.Dot(vecs[i], centroid); sim > bestSim {
+						best, bestSim = c, sim
+					}
+				}
+				if assign[i] != best {
+					assign[i] = best
+					changed[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range changed {
+		total += c
+	}
+	return total
+}
+
+func clone(v []float32) []float32 {
+	return append([]float32(nil), v...)
+}
@@ -0,0 +1,116 @@
+package cluster
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "are": true, "was": true, "you": true, "your": true, "how": true,
+	"what": true, "why": true, "when": true, "who": true, "its": true, "into": true,
+	"about": true, "our": true, "can": true, "not": true, "but": true, "all": true,
+	"have": true, "has": true, "will": true, "more": true, "one": true, "new": true,
+	"they": true, "their": true, "out": true, "use": true, "using": true, "than": true,
+	"there": true, "which": true, "been": true, "also": true, "just": true, "like": true,
+	"some": true, "them": true, "these": true, "were": true, "would": true, "should": true,
+	"http": true, "https": true, "www": true, "com": true, "org": true, "html": true,
+}
+
+// terms returns the distinct label candidate words of an entry.
+func terms(e index.IndexEntry) map[string]bool {
+	text := strings.ToLower(e.Title + " " + e.Description + " " + strings.Join(e.Highlights, " "))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		if len(w) < 3 || stopwords[w] || isNumber(w) {
+			continue
+		}
+		set[w] = true
+	}
+	return set
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// distinctiveTerms ranks the words of a cluster's entries by how much more
+// often they occur in the cluster than in the whole library (TF-IDF with
+// document frequency inside the cluster as TF).
+func distinctiveTerms(members []int, docTerms []map[string]bool, corpusDF map[string]int, limit int) []string {
+	clusterDF := make(map[string]int)
+	for _, i := range members {
+		for t := range docTerms[i] {
+			clusterDF[t]++
+		}
+	}
+
+	type scored struct {
+		term  string
+		score float64
+	}
+	n := float64(len(docTerms))
+	var ranked []scored
+	for t, df := range clusterDF {
+		if df < 2 && len(members) > 2 {
+			continue
+		}
+		tf := float64(df) / float64(len(members))
+		idf := math.Log(n / float64(corpusDF[t]))
+		ranked = append(ranked, scored{t, tf * idf})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].term < ranked[j].term
+	})
+
+	out := make([]string, 0, limit)
+	for _, s := range ranked {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, s.term)
+	}
+	return out
+}
+
+// commonTags returns the most frequent tags among a cluster's entries.
+func commonTags(entries []index.IndexEntry, members []int, limit int) []string {
+	counts := make(map[string]int)
+	for _, i := range members {
+		for _, t := range entries[i].Tags {
+			counts[t]++
+		}
+	}
+
+	tags := make([]string, 0, len(counts))
+	for t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags
+}
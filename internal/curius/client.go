@@ -65,6 +65,29 @@ func (c *Client) FetchAllLinks() ([]Link, error) {
 	return all, nil
 }
 
+// FetchFollowing returns the users the client's user follows.
+func (c *Client) FetchFollowing() ([]User, error) {
+	url := fmt.Sprintf("%s/%s", baseURL, c.userID)
+	log.Printf("Fetching following list: %s", url)
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch user %s: %w", c.userID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch user %s: status %d", c.userID, resp.StatusCode)
+	}
+
+	var apiResp apiUserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("decode user %s: %w", c.userID, err)
+	}
+
+	return apiResp.User.FollowingUsers, nil
+}
+
 func convertLink(al apiLink) Link {
 	l := Link{
 		ID:          al.ID,
@@ -0,0 +1,97 @@
+package curius
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// LoadDump reads bookmarks saved from the Curius API instead of fetching them.
+//
+// path is either a directory of captured /links?page=N responses, read in
+// page order (the page number is taken from the last number in each file
+// name), or a single export file holding one response or a JSON array of links.
+func LoadDump(path string) ([]Link, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat dump: %w", err)
+	}
+
+	if !info.IsDir() {
+		return loadDumpFile(path)
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list dump dir: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .json files in %s", path)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return pageNumber(files[i]) < pageNumber(files[j])
+	})
+
+	var all []Link
+	for _, f := range files {
+		links, err := loadDumpFile(f)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, links...)
+	}
+
+	return all, nil
+}
+
+var lastNumber = regexp.MustCompile(`(\d+)\D*$`)
+
+// pageNumber extracts the page from names like "links?page=3.json" or "page-3.json".
+func pageNumber(path string) int {
+	m := lastNumber.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func loadDumpFile(path string) ([]Link, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read dump file: %w", err)
+	}
+
+	var apiLinks []apiLink
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &apiLinks); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+	} else {
+		var apiResp apiResponse
+		if err := json.Unmarshal(data, &apiResp); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		apiLinks = apiResp.UserSaved
+	}
+
+	links := make([]Link, 0, len(apiLinks))
+	for _, al := range apiLinks {
+		links = append(links, convertLink(al))
+	}
+	return links, nil
+}
+
+// IsDump reports whether data looks like a captured /links response.
+func IsDump(data []byte) bool {
+	var probe struct {
+		UserSaved json.RawMessage `json:"userSaved"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.UserSaved != nil
+}
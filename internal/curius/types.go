@@ -17,6 +17,14 @@ type Tag struct {
 	Name string `json:"name"`
 }
 
+// User is a Curius account, as listed in another user's follows.
+type User struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	UserLink  string `json:"userLink"`
+}
+
 // apiResponse is the raw response from the Curius API.
 type apiResponse struct {
 	UserSaved []apiLink `json:"userSaved"`
@@ -41,3 +49,11 @@ type apiHighlight struct {
 	ID        int    `json:"id"`
 	Highlight string `json:"highlight"`
 }
+
+// apiUserResponse is the raw response from the Curius user profile endpoint.
+type apiUserResponse struct {
+	User struct {
+		ID             int    `json:"id"`
+		FollowingUsers []User `json:"followingUsers"`
+	} `json:"user"`
+}
@@ -0,0 +1,124 @@
+package dedupe
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that identify the referrer rather than the page.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"igshid":  true,
+	"ref":     true,
+	"ref_src": true,
+	"ref_url": true,
+	"si":      true,
+	"cmpid":   true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+}
+
+// hostPrefixes are subdomains that serve the same content as the bare domain.
+var hostPrefixes = []string{"www.", "m.", "mobile.", "amp.", "old."}
+
+// hostAliases map domains to the one they mirror.
+var hostAliases = map[string]string{
+	"x.com":              "twitter.com",
+	"youtu.be":           "youtube.com",
+	"export.arxiv.org":   "arxiv.org",
+	"en.m.wikipedia.org": "en.wikipedia.org",
+}
+
+var (
+	arxivPath   = regexp.MustCompile(`^/(?:abs|pdf|html)/([^/]+?)(?:v\d+)?(?:\.pdf)?$`)
+	trailingAMP = regexp.MustCompile(`/amp/?$`)
+)
+
+// CanonicalURL normalizes a bookmark URL so that variants of the same page
+// compare equal: scheme, "www."/mobile subdomains, fragments, tracking
+// parameters and trailing slashes are dropped, query parameters are sorted,
+// and known aliases (arXiv abs/pdf, youtu.be, x.com) are rewritten.
+// Unparseable URLs are returned lowercased.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if alias, ok := hostAliases[host]; ok {
+		host = alias
+	}
+	for _, p := range hostPrefixes {
+		if strings.HasPrefix(host, p) && strings.Count(host, ".") > 1 {
+			host = strings.TrimPrefix(host, p)
+			break
+		}
+	}
+	if alias, ok := hostAliases[host]; ok {
+		host = alias
+	}
+
+	path := u.EscapedPath()
+	query := u.Query()
+
+	switch host {
+	case "arxiv.org":
+		if m := arxivPath.FindStringSubmatch(path); m != nil {
+			path = "/abs/" + m[1]
+		}
+	case "youtube.com":
+		if u.Hostname() == "youtu.be" {
+			query.Set("v", strings.TrimPrefix(path, "/"))
+			path = "/watch"
+		}
+		query.Del("feature")
+		query.Del("t")
+	}
+
+	path = trailingAMP.ReplaceAllString(path, "")
+	path = strings.TrimRight(path, "/")
+	for _, idx := range []string{"/index.html", "/index.htm", "/index.php"} {
+		path = strings.TrimSuffix(path, idx)
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "utm_") || trackingParams[lk] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(host)
+	b.WriteString(path)
+	for i, k := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		vals := query[k]
+		sort.Strings(vals)
+		for j, v := range vals {
+			if j > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,242 @@
+package dedupe
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// DefaultThreshold is the cosine similarity above which two bookmarks are
+// considered the same page saved twice.
+const DefaultThreshold = 0.95
+
+// Member is a bookmark in a duplicate group, scored against the group's
+// representative (its oldest save).
+type Member struct {
+	Entry      index.IndexEntry
+	Similarity float32
+}
+
+// Group is a set of bookmarks that point at the same content.
+type Group struct {
+	ID        int
+	Canonical string
+	Members   []Member
+	// MinSimilarity is the lowest member similarity to the representative.
+	MinSimilarity float32
+}
+
+// Result is a duplicate analysis of the store at one version.
+type Result struct {
+	Version   uint64
+	Threshold float32
+	Groups    []Group
+	// byKey maps an entry key to its index in Groups.
+	byKey map[string]int
+}
+
+// GroupOf returns the group containing the entry with key, if any.
+func (r *Result) GroupOf(key string) (*Group, bool) {
+	if r == nil {
+		return nil, false
+	}
+	i, ok := r.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	return &r.Groups[i], true
+}
+
+// Finder computes duplicate groups over an index.Store and caches the
+// result per store version.
+type Finder struct {
+	store *index.Store
+
+	mu         sync.Mutex
+	latest     *Result
+	refreshing bool
+}
+
+func NewFinder(store *index.Store) *Finder {
+	return &Finder{store: store}
+}
+
+// Groups returns the duplicate groups at threshold, computing them if the
+// store changed since the last run.
+func (f *Finder) Groups(threshold float32) *Result {
+	version := f.store.Version()
+
+	f.mu.Lock()
+	if f.latest != nil && f.latest.Version == version && f.latest.Threshold == threshold {
+		r := f.latest
+		f.mu.Unlock()
+		return r
+	}
+	f.mu.Unlock()
+
+	r := Find(f.store.Entries(), threshold)
+	r.Version = version
+
+	f.mu.Lock()
+	if threshold == DefaultThreshold {
+		f.latest = r
+	}
+	f.mu.Unlock()
+	return r
+}
+
+// Cached returns the most recent default-threshold result without
+// computing, even if the store has changed since. When it is stale a
+// refresh is started in the background. It returns nil before the first run.
+func (f *Finder) Cached() *Result {
+	version := f.store.Version()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if (f.latest == nil || f.latest.Version != version) && !f.refreshing {
+		f.refreshing = true
+		go func() {
+			f.Groups(DefaultThreshold)
+			f.mu.Lock()
+			f.refreshing = false
+			f.mu.Unlock()
+		}()
+	}
+	return f.latest
+}
+
+// Find groups entries that share a canonical URL or whose embeddings have
+// cosine similarity of at least threshold.
+func Find(entries []index.IndexEntry, threshold float32) *Result {
+	n := len(entries)
+	uf := newUnionFind(n)
+
+	// Same canonical URL
+	canonical := make([]string, n)
+	firstByURL := make(map[string]int, n)
+	for i, e := range entries {
+		canonical[i] = CanonicalURL(e.URL)
+		if j, ok := firstByURL[canonical[i]]; ok {
+			uf.union(i, j)
+		} else {
+			firstByURL[canonical[i]] = i
+		}
+	}
+
+	// Near-identical embeddings. Vectors are normalized once so each pair is
+	// a dot product; rows are split across CPUs.
+	vecs := make([][]float32, n)
+	for i, e := range entries {
+		vecs[i] = index.Normalize(e.Embedding)
+	}
+
+	type pair struct{ i, j int }
+	pairs := make(chan []pair)
+	var wg sync.WaitGroup
+	workers := runtime.NumCPU()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			var found []pair
+			for i := w; i < n; i += workers {
+				if vecs[i] == nil {
+					continue
+				}
+				for j := i + 1; j < n; j++ {
+					if vecs[j] != nil && len(vecs[j]) == len(vecs[i]) && index.Dot(vecs[i], vecs[j]) >= threshold {
+						found = append(found, pair{i, j})
+					}
+				}
+			}
+			pairs <- found
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(pairs)
+	}()
+	for found := range pairs {
+		for _, p := range found {
+			uf.union(p.i, p.j)
+		}
+	}
+
+	// Collect groups of two or more
+	members := make(map[int][]int)
+	for i := 0; i < n; i++ {
+		root := uf.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	r := &Result{Threshold: threshold, byKey: make(map[string]int)}
+	for _, idxs := range members {
+		if len(idxs) < 2 {
+			continue
+		}
+		sort.Slice(idxs, func(a, b int) bool {
+			return entries[idxs[a]].CreatedAt.Before(entries[idxs[b]].CreatedAt)
+		})
+
+		rep := idxs[0]
+		g := Group{Canonical: canonical[rep], MinSimilarity: 1}
+		for _, i := range idxs {
+			sim := float32(1)
+			if i != rep && canonical[i] != canonical[rep] {
+				sim = index.CosineSimilarity(entries[rep].Embedding, entries[i].Embedding)
+			}
+			if sim < g.MinSimilarity {
+				g.MinSimilarity = sim
+			}
+			g.Members = append(g.Members, Member{Entry: entries[i], Similarity: sim})
+		}
+		r.Groups = append(r.Groups, g)
+	}
+
+	// Largest groups first, then most recent
+	sort.Slice(r.Groups, func(a, b int) bool {
+		ga, gb := r.Groups[a], r.Groups[b]
+		if len(ga.Members) != len(gb.Members) {
+			return len(ga.Members) > len(gb.Members)
+		}
+		return ga.Members[0].Entry.CreatedAt.After(gb.Members[0].Entry.CreatedAt)
+	})
+	for i := range r.Groups {
+		r.Groups[i].ID = i + 1
+		for _, m := range r.Groups[i].Members {
+			r.byKey[m.Entry.Key()] = i
+		}
+	}
+
+	return r
+}
+
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	return &unionFind{parent: p}
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(a, b int) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u.parent[ra] = rb
+	}
+}
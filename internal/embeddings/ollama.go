@@ -24,6 +24,16 @@ func NewClient(host, model string) *Client {
 	}
 }
 
+// SetTimeout sets the timeout of each request to Ollama.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// Model returns the name of the embedding model.
+func (c *Client) Model() string {
+	return c.model
+}
+
 // Embed returns the embedding vector for the given text.
 func (c *Client) Embed(text string) ([]float32, error) {
 	req := embedRequest{
@@ -67,3 +77,27 @@ func (c *Client) IsHealthy() bool {
 	resp.Body.Close()
 	return resp.StatusCode == http.StatusOK
 }
+
+// Models lists the models installed in Ollama.
+func (c *Client) Models() ([]string, error) {
+	resp, err := c.httpClient.Get(c.host + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("ollama tags request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama tags: status %d", resp.StatusCode)
+	}
+
+	var result tagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode tags response: %w", err)
+	}
+
+	names := make([]string, len(result.Models))
+	for i, m := range result.Models {
+		names[i] = m.Name
+	}
+	return names, nil
+}
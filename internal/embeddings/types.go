@@ -10,3 +10,10 @@ type embedRequest struct {
 type embedResponse struct {
 	Embeddings [][]float32 `json:"embeddings"`
 }
+
+// tagsResponse is the response from Ollama's /api/tags endpoint.
+type tagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
@@ -0,0 +1,240 @@
+// Package export writes the index in portable formats for notebooks and
+// other tools: JSONL, CSV, Markdown grouped by tag, and a float32 .npy
+// matrix with an ID sidecar. Output is streamed entry by entry.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// Export formats.
+const (
+	FormatJSONL    = "jsonl"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatNPY      = "npy"
+)
+
+// Formats lists the export formats.
+var Formats = []string{FormatJSONL, FormatCSV, FormatMarkdown, FormatNPY}
+
+// ParseFormat validates an export format name; "md" is short for markdown.
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", FormatJSONL:
+		return FormatJSONL, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	case FormatNPY:
+		return FormatNPY, nil
+	}
+	return "", fmt.Errorf("invalid export format %q (want %s)", s, strings.Join(Formats, ", "))
+}
+
+// Record is one bookmark in a JSONL export.
+type Record struct {
+	ID          int       `json:"id"`
+	Source      string    `json:"source"`
+	UserID      string    `json:"userId,omitempty"`
+	Network     bool      `json:"network,omitempty"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Tags        []string  `json:"tags,omitempty"`
+	Highlights  []string  `json:"highlights,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Embedding   []float32 `json:"embedding,omitempty"`
+	// Model is the embedding model, set when the embedding is included.
+	Model string `json:"model,omitempty"`
+}
+
+// NewRecord converts an index entry, with its embedding if vectors is set.
+func NewRecord(e index.IndexEntry, vectors bool) Record {
+	rec := Record{
+		ID:          e.ID,
+		Source:      e.SourceName(),
+		UserID:      e.UserID,
+		Network:     e.Network,
+		Title:       e.Title,
+		URL:         e.URL,
+		Tags:        e.Tags,
+		Highlights:  e.Highlights,
+		Description: e.Description,
+		CreatedAt:   e.CreatedAt,
+	}
+	if vectors {
+		rec.Embedding = e.Embedding
+	}
+	return rec
+}
+
+// WriteJSONL writes one JSON object per bookmark and returns how many it wrote.
+func WriteJSONL(w io.Writer, store *index.Store, filter index.Filter, vectors bool) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	model := store.Model()
+	n := 0
+	err := store.Each(filter, func(e index.IndexEntry) error {
+		n++
+		rec := NewRecord(e, vectors)
+		if vectors {
+			rec.Model = model
+		}
+		return enc.Encode(rec)
+	})
+	if err != nil {
+		return n, fmt.Errorf("write export: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return n, fmt.Errorf("write export: %w", err)
+	}
+	return n, nil
+}
+
+// csvHeader is the CSV column order. Tags are separated by "|" and
+// highlights by newlines.
+var csvHeader = []string{"id", "source", "user", "title", "url", "tags", "description", "highlights", "created_at"}
+
+// WriteCSV writes a header row and one row per bookmark.
+func WriteCSV(w io.Writer, store *index.Store, filter index.Filter) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return 0, fmt.Errorf("write export: %w", err)
+	}
+
+	n := 0
+	err := store.Each(filter, func(e index.IndexEntry) error {
+		n++
+		created := ""
+		if !e.CreatedAt.IsZero() {
+			created = e.CreatedAt.UTC().Format(time.RFC3339)
+		}
+		return cw.Write([]string{
+			fmt.Sprint(e.ID),
+			e.SourceName(),
+			e.UserID,
+			e.Title,
+			e.URL,
+			strings.Join(e.Tags, "|"),
+			e.Description,
+			strings.Join(e.Highlights, "\n"),
+			created,
+		})
+	})
+	if err != nil {
+		return n, fmt.Errorf("write export: %w", err)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return n, fmt.Errorf("write export: %w", err)
+	}
+	return n, nil
+}
+
+// WriteMarkdown writes a section per tag, alphabetically with untagged
+// bookmarks last, listing each bookmark newest first with its highlights.
+// A bookmark with several tags appears under each of them.
+func WriteMarkdown(w io.Writer, store *index.Store, filter index.Filter) (int, error) {
+	// Sorting and grouping need only keys and dates; each entry is fetched
+	// again as it is written, so the export never holds them all
+	type item struct {
+		key     string
+		savedAt time.Time
+		tags    []string
+	}
+	var items []item
+	store.Each(filter, func(e index.IndexEntry) error {
+		var tags []string
+		seen := make(map[string]bool, len(e.Tags))
+		for _, tag := range e.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		items = append(items, item{key: e.Key(), savedAt: e.CreatedAt, tags: tags})
+		return nil
+	})
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].savedAt.After(items[j].savedAt)
+	})
+
+	groups := make(map[string][]int)
+	var tags []string
+	var none []int
+	for i, it := range items {
+		for _, tag := range it.tags {
+			if _, ok := groups[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			groups[tag] = append(groups[tag], i)
+		}
+		if len(it.tags) == 0 {
+			none = append(none, i)
+		}
+	}
+	sort.Strings(tags)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Bookmarks\n\n%d bookmarks, exported %s\n", len(items), time.Now().Format("2006-01-02"))
+	section := func(heading string, positions []int) {
+		fmt.Fprintf(bw, "\n## %s\n\n", markdownEscape(heading))
+		for _, i := range positions {
+			// Skip bookmarks deleted since they were listed
+			if e, ok := store.Get(items[i].key); ok {
+				writeMarkdownEntry(bw, e)
+			}
+		}
+	}
+	for _, tag := range tags {
+		section(tag, groups[tag])
+	}
+	if len(none) > 0 {
+		section("Untagged", none)
+	}
+	if err := bw.Flush(); err != nil {
+		return len(items), fmt.Errorf("write export: %w", err)
+	}
+	return len(items), nil
+}
+
+func writeMarkdownEntry(w io.Writer, e index.IndexEntry) {
+	title := e.Title
+	if title == "" {
+		title = e.URL
+	}
+	fmt.Fprintf(w, "- [%s](%s)", markdownEscape(title), strings.ReplaceAll(e.URL, ")", "%29"))
+	if !e.CreatedAt.IsZero() {
+		fmt.Fprintf(w, " (%s)", e.CreatedAt.Format("2006-01-02"))
+	}
+	fmt.Fprintln(w)
+	if e.Description != "" {
+		fmt.Fprintf(w, "  %s\n", oneLine(e.Description))
+	}
+	for _, h := range e.Highlights {
+		fmt.Fprintf(w, "  > %s\n", oneLine(h))
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`")
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(oneLine(s))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
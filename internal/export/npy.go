@@ -0,0 +1,149 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// Names of the files in the archive WriteZip writes.
+const (
+	NPYFile = "vectors.npy"
+	IDsFile = "ids.txt"
+)
+
+// Matrix is the embedding matrix of an export: one row per entry key, all
+// of dimension Dim. Skipped counts entries without an embedding of that
+// size. Rows are read from the store as they are written, so a matrix
+// holds keys rather than vectors.
+type Matrix struct {
+	Keys    []string
+	Dim     int
+	Skipped int
+
+	store *index.Store
+}
+
+// NewMatrix lists the embeddings matching filter. Entries whose embedding
+// is missing or of a different size than most are skipped.
+func NewMatrix(store *index.Store, filter index.Filter) Matrix {
+	type row struct {
+		key string
+		dim int
+	}
+	var rows []row
+	dims := make(map[int]int)
+	store.Each(filter, func(e index.IndexEntry) error {
+		rows = append(rows, row{key: e.Key(), dim: len(e.Embedding)})
+		if len(e.Embedding) > 0 {
+			dims[len(e.Embedding)]++
+		}
+		return nil
+	})
+
+	m := Matrix{store: store}
+	for dim, n := range dims {
+		if n > dims[m.Dim] || (n == dims[m.Dim] && dim > m.Dim) {
+			m.Dim = dim
+		}
+	}
+	for _, r := range rows {
+		if m.Dim > 0 && r.dim == m.Dim {
+			m.Keys = append(m.Keys, r.key)
+		} else {
+			m.Skipped++
+		}
+	}
+	return m
+}
+
+// WriteNPY writes the matrix as a little-endian float32 .npy array of
+// shape (rows, dim). A row whose entry was deleted or re-embedded at
+// another size since NewMatrix is written as zeros, keeping the shape.
+func (m Matrix) WriteNPY(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(npyHeader(len(m.Keys), m.Dim)); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	row := make([]byte, 4*m.Dim)
+	for _, key := range m.Keys {
+		clear(row)
+		if e, ok := m.store.Get(key); ok && len(e.Embedding) == m.Dim {
+			for i, v := range e.Embedding {
+				binary.LittleEndian.PutUint32(row[4*i:], math.Float32bits(v))
+			}
+		}
+		if _, err := bw.Write(row); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
+
+// WriteIDs writes the sidecar: the key of each matrix row ("source/user/id",
+// see index.EntryKey), one per line, in row order.
+func (m Matrix) WriteIDs(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, key := range m.Keys {
+		bw.WriteString(key)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
+
+// WriteZip writes a zip archive holding the matrix as NPYFile and the
+// sidecar as IDsFile, for exports that must be a single file.
+func (m Matrix) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: IDsFile, Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	if err := m.WriteIDs(f); err != nil {
+		return err
+	}
+	// The vectors barely compress, so store them
+	f, err = zw.CreateHeader(&zip.FileHeader{Name: NPYFile, Method: zip.Store, Modified: now})
+	if err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	if err := m.WriteNPY(f); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
+
+// npyHeader returns the .npy version 1.0 preamble for a float32 matrix,
+// padded so the data starts on a 64-byte boundary.
+func npyHeader(rows, dim int) []byte {
+	dict := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }", rows, dim)
+	// magic (6) + version (2) + header length (2) + dict + padding + newline
+	pad := 64 - (10+len(dict)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	dict += strings.Repeat(" ", pad) + "\n"
+
+	h := make([]byte, 0, 10+len(dict))
+	h = append(h, "\x93NUMPY"...)
+	h = append(h, 1, 0)
+	h = binary.LittleEndian.AppendUint16(h, uint16(len(dict)))
+	return append(h, dict...)
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aryannaik/curius-search/internal/textutil"
+)
+
+// WriteGraphML writes g as GraphML with title, url, tags and depth node
+// attributes and a weight edge attribute.
+func WriteGraphML(w io.Writer, g Graph) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="title" for="node" attr.name="title" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="url" for="node" attr.name="url" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="tags" for="node" attr.name="tags" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="depth" for="node" attr.name="depth" attr.type="int"/>` + "\n")
+	b.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="double"/>` + "\n")
+	b.WriteString(`  <graph id="bookmarks" edgedefault="undirected">` + "\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", xmlEscape(n.Key))
+		fmt.Fprintf(&b, "      <data key=\"title\">%s</data>\n", xmlEscape(n.Title))
+		fmt.Fprintf(&b, "      <data key=\"url\">%s</data>\n", xmlEscape(n.URL))
+		fmt.Fprintf(&b, "      <data key=\"tags\">%s</data>\n", xmlEscape(strings.Join(n.Tags, ", ")))
+		fmt.Fprintf(&b, "      <data key=\"depth\">%d</data>\n", n.Depth)
+		b.WriteString("    </node>\n")
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, xmlEscape(e.Source), xmlEscape(e.Target))
+		fmt.Fprintf(&b, "      <data key=\"weight\">%.4f</data>\n", e.Weight)
+		b.WriteString("    </edge>\n")
+	}
+
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDOT writes g in Graphviz DOT format. Seeds are drawn bold and edge
+// pen width follows similarity.
+func WriteDOT(w io.Writer, g Graph) error {
+	var b strings.Builder
+	b.WriteString("graph bookmarks {\n")
+	b.WriteString("  node [shape=box, style=rounded, fontsize=10];\n")
+
+	for _, n := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s, URL=%s, tooltip=%s", dotQuote(textutil.Truncate(n.Title, 60)), dotQuote(n.URL), dotQuote(n.URL))
+		if n.Seed {
+			attrs += ", style=\"rounded,bold\""
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.Key), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -- %s [weight=%.4f, penwidth=%.2f];\n", dotQuote(e.Source), dotQuote(e.Target), e.Weight, 0.5+3*max(e.Weight, 0))
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", " ")
+	return `"` + s + `"`
+}
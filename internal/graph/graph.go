@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// Options bounds how far a graph expands from its seeds.
+type Options struct {
+	// K is the number of nearest neighbours considered per node.
+	K int
+	// Threshold is the minimum cosine similarity for an edge.
+	Threshold float32
+	// Depth is how many hops to expand from the seeds.
+	Depth int
+	// MaxNodes stops expansion once the graph is this large.
+	MaxNodes int
+}
+
+// Node is a bookmark in the graph.
+type Node struct {
+	Key    string   `json:"key"`
+	ID     int      `json:"id"`
+	UserID string   `json:"userId,omitempty"`
+	Title  string   `json:"title"`
+	URL    string   `json:"url"`
+	Tags   []string `json:"tags"`
+	// Depth is the number of hops from the nearest seed.
+	Depth int  `json:"depth"`
+	Seed  bool `json:"seed,omitempty"`
+}
+
+// Edge links two bookmarks by the cosine similarity of their embeddings.
+type Edge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Weight float32 `json:"weight"`
+}
+
+// Graph is a k-nearest-neighbour graph around a set of seed bookmarks.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build expands a kNN graph breadth-first from seeds: each node is linked to
+// its K most similar bookmarks above Threshold, and newly reached bookmarks
+// are expanded in turn until Depth hops or MaxNodes nodes.
+func Build(store *index.Store, seeds []index.IndexEntry, opts Options) Graph {
+	var g Graph
+	nodes := make(map[string]int)
+	edges := make(map[[2]string]bool)
+
+	addNode := func(e index.IndexEntry, depth int, seed bool) bool {
+		if _, ok := nodes[e.Key()]; ok {
+			return false
+		}
+		nodes[e.Key()] = len(g.Nodes)
+		g.Nodes = append(g.Nodes, Node{
+			Key:    e.Key(),
+			ID:     e.ID,
+			UserID: e.UserID,
+			Title:  e.Title,
+			URL:    e.URL,
+			Tags:   e.Tags,
+			Depth:  depth,
+			Seed:   seed,
+		})
+		return true
+	}
+
+	var frontier []index.IndexEntry
+	for _, e := range seeds {
+		if addNode(e, 0, true) {
+			frontier = append(frontier, e)
+		}
+	}
+
+	for depth := 1; depth <= opts.Depth && len(frontier) > 0; depth++ {
+		var next []index.IndexEntry
+		for _, e := range frontier {
+			for _, hit := range store.SearchByVector(e.Embedding, opts.K, e.Key()) {
+				if hit.Score < opts.Threshold {
+					break
+				}
+				if len(g.Nodes) >= opts.MaxNodes {
+					if _, ok := nodes[hit.Entry.Key()]; !ok {
+						continue
+					}
+				}
+
+				if addNode(hit.Entry, depth, false) {
+					next = append(next, hit.Entry)
+				}
+
+				a, b := e.Key(), hit.Entry.Key()
+				if a > b {
+					a, b = b, a
+				}
+				if !edges[[2]string{a, b}] {
+					edges[[2]string{a, b}] = true
+					g.Edges = append(g.Edges, Edge{Source: e.Key(), Target: hit.Entry.Key(), Weight: hit.Score})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	// Edges among nodes reached at the last hop aren't explored by the BFS;
+	// connect them so the exported graph reflects all similarities in view.
+	for _, e := range frontier {
+		for _, hit := range store.SearchByVector(e.Embedding, opts.K, e.Key()) {
+			if hit.Score < opts.Threshold {
+				break
+			}
+			if _, ok := nodes[hit.Entry.Key()]; !ok {
+				continue
+			}
+			a, b := e.Key(), hit.Entry.Key()
+			if a > b {
+				a, b = b, a
+			}
+			if !edges[[2]string{a, b}] {
+				edges[[2]string{a, b}] = true
+				g.Edges = append(g.Edges, Edge{Source: e.Key(), Target: hit.Entry.Key(), Weight: hit.Score})
+			}
+		}
+	}
+
+	sort.SliceStable(g.Edges, func(i, j int) bool { return g.Edges[i].Weight > g.Edges[j].Weight })
+	return g
+}
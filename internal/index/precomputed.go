@@ -0,0 +1,46 @@
+package index
+
+// Dim returns the embedding dimension of the index: that of its entries,
+// else of its imported embeddings, or 0 if it has neither.
+func (s *Store) Dim() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.entries {
+		if len(e.Embedding) > 0 {
+			return len(e.Embedding)
+		}
+	}
+	for _, v := range s.precomputed {
+		return len(v.Embedding)
+	}
+	return 0
+}
+
+// AddPrecomputed records an imported embedding for the bookmark with key,
+// used instead of embedding it when it is indexed. It reports false if the
+// bookmark is already indexed.
+func (s *Store) AddPrecomputed(key string, v PrecomputedVector) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mutate(walRecord{Op: opVector, Key: key, Vector: &v}) > 0
+}
+
+// Precomputed returns the imported embedding for the bookmark with key if
+// one was made with model.
+func (s *Store) Precomputed(key, model string) ([]float32, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.precomputed[key]
+	if !ok || v.Model != model {
+		return nil, false
+	}
+	return v.Embedding, true
+}
+
+// PrecomputedCount returns the number of imported embeddings waiting for
+// their bookmark to be indexed.
+func (s *Store) PrecomputedCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.precomputed)
+}
@@ -2,8 +2,8 @@ package index
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,97 +11,406 @@ import (
 	"sync"
 	"time"
 
-	"github.com/aryannaik/curius-search/internal/curius"
+	"github.com/aryannaik/curius-search/internal/source"
 )
 
 type Store struct {
+	saveMu  sync.Mutex
 	mu      sync.RWMutex
 	entries []IndexEntry
-	idSet   map[int]bool
-	path    string
+	// byKey maps each entry's key to its position in entries.
+	byKey   map[string]int
+	users   map[string]UserState
+	model   string
+	version uint64
+	// precomputed holds imported embeddings of bookmarks not indexed yet.
+	precomputed map[string]PrecomputedVector
+	path        string
+	walPath     string
+
+	// pending holds mutations not yet appended to the log; seq numbers
+	// every logged mutation.
+	pending       []walRecord
+	seq           uint64
+	walBytes      int64
+	walTorn       bool
+	snapshotBytes int64
+	recovered     int
+	// readOnly stores were opened with LoadReadOnly and never write.
+	readOnly bool
+
+	semanticWeight float32
 }
 
 func NewStore(dataDir string) *Store {
 	return &Store{
-		idSet: make(map[int]bool),
-		path:  filepath.Join(dataDir, "index.json"),
+		byKey:          make(map[string]int),
+		users:          make(map[string]UserState),
+		precomputed:    make(map[string]PrecomputedVector),
+		path:           filepath.Join(dataDir, "index.json"),
+		walPath:        filepath.Join(dataDir, "index.log"),
+		semanticWeight: DefaultSemanticWeight,
 	}
 }
 
-// LoadFromDisk loads the index from the JSON file. Returns nil if file doesn't exist.
+// EntryKey builds the store key for a bookmark from source owned by userID.
+func EntryKey(source, userID string, id int) string {
+	return fmt.Sprintf("%s/%s/%d", source, userID, id)
+}
+
+// NetworkMode controls whether bookmarks of followed users are searched.
+type NetworkMode string
+
+const (
+	NetworkInclude NetworkMode = "include"
+	NetworkExclude NetworkMode = "exclude"
+	NetworkOnly    NetworkMode = "only"
+)
+
+// ParseNetworkMode parses a network mode, treating "" as NetworkInclude.
+func ParseNetworkMode(s string) (NetworkMode, error) {
+	switch NetworkMode(s) {
+	case "", NetworkInclude:
+		return NetworkInclude, nil
+	case NetworkExclude, NetworkOnly:
+		return NetworkMode(s), nil
+	}
+	return "", fmt.Errorf("invalid network mode %q (want include, exclude or only)", s)
+}
+
+// Filter restricts which entries a search considers. The zero value matches everything.
+type Filter struct {
+	UserID  string
+	Source  string
+	Network NetworkMode
+	Dead    bool
+	Tag     string
+	// IndexedAfter, when set, matches only entries indexed after it.
+	IndexedAfter time.Time
+	// Keys, when non-nil, restricts matches to entries with these keys.
+	Keys map[string]bool
+}
+
+// Match reports whether the entry passes the filter.
+func (f Filter) Match(e IndexEntry) bool {
+	if f.UserID != "" && e.UserID != f.UserID {
+		return false
+	}
+	if f.Source != "" && e.SourceName() != f.Source {
+		return false
+	}
+	if f.Dead && !e.IsDead() {
+		return false
+	}
+	if f.Keys != nil && !f.Keys[e.Key()] {
+		return false
+	}
+	if f.Tag != "" && !hasTag(e, f.Tag) {
+		return false
+	}
+	if !f.IndexedAfter.IsZero() && !e.IndexedAt.After(f.IndexedAfter) {
+		return false
+	}
+	switch f.Network {
+	case NetworkExclude:
+		if e.Network {
+			return false
+		}
+	case NetworkOnly:
+		if !e.Network {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrReadOnly is returned when saving a store opened with LoadReadOnly.
+var ErrReadOnly = errors.New("index opened read-only")
+
+// LoadFromDisk loads the index from the JSON file and replays the log onto
+// it. Returns nil if neither exists.
 func (s *Store) LoadFromDisk() error {
+	return s.load(false)
+}
+
+// LoadReadOnly loads the index like LoadFromDisk but never writes to the
+// data dir, so it is safe beside a running server. Saving the store fails
+// with ErrReadOnly; mutations only change the in-memory copy.
+func (s *Store) LoadReadOnly() error {
+	return s.load(true)
+}
+
+func (s *Store) load(readOnly bool) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.readOnly = readOnly
 
+	var idx Index
 	data, err := os.ReadFile(s.path)
-	if os.IsNotExist(err) {
-		return nil
-	}
-	if err != nil {
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
 		return fmt.Errorf("read index file: %w", err)
+	default:
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return fmt.Errorf("decode index: %w", err)
+		}
 	}
+	s.snapshotBytes = int64(len(data))
+	s.reset(idx)
+	s.walBytes = 0
 
-	var idx Index
-	if err := json.Unmarshal(data, &idx); err != nil {
-		return fmt.Errorf("decode index: %w", err)
+	if err := s.replayLog(idx.Seq); err != nil {
+		return err
 	}
+	s.version++
+
+	return nil
+}
+
+// reindex rebuilds byKey after entries were removed or re-keyed. Callers
+// hold s.mu.
+func (s *Store) reindex() {
+	s.byKey = make(map[string]int, len(s.entries))
+	for i, e := range s.entries {
+		s.byKey[e.Key()] = i
+	}
+}
 
+// has reports whether an entry with key is indexed. Callers hold s.mu.
+func (s *Store) has(key string) bool {
+	_, ok := s.byKey[key]
+	return ok
+}
+
+// reset replaces the contents of the store with idx. Callers hold s.mu.
+func (s *Store) reset(idx Index) {
 	s.entries = idx.Entries
-	s.idSet = make(map[int]bool, len(idx.Entries))
-	for _, e := range idx.Entries {
-		s.idSet[e.ID] = true
+	s.reindex()
+
+	s.users = idx.Users
+	if s.users == nil {
+		s.users = make(map[string]UserState)
+	}
+	s.model = idx.Model
+	s.precomputed = idx.Precomputed
+	if s.precomputed == nil {
+		s.precomputed = make(map[string]PrecomputedVector)
 	}
+	s.seq = idx.Seq
+	s.pending = nil
+}
 
+// SaveToDisk appends the mutations since the last save to the log, which
+// costs O(changes). Once the log outgrows half the index file (and at least
+// 1 MiB) it is compacted into a new index file. Concurrent savers (indexer,
+// link checker) are serialised.
+func (s *Store) SaveToDisk() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	if err := s.flushLocked(); err != nil {
+		return err
+	}
+	if s.walBytes >= max(minCompactBytes, s.snapshotBytes/2) {
+		return s.compactLocked()
+	}
 	return nil
 }
 
-// SaveToDisk persists the index to the JSON file.
-func (s *Store) SaveToDisk() error {
+// hasTag reports whether the entry carries tag, ignoring case.
+func hasTag(e IndexEntry, tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Has returns true if the user's bookmark ID from source is already indexed.
+func (s *Store) Has(source, userID string, id int) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.has(EntryKey(source, userID, id))
+}
 
-	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
-		return fmt.Errorf("create data dir: %w", err)
-	}
+// HasKey reports whether an entry with the given key (see EntryKey) is indexed.
+func (s *Store) HasKey(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.has(key)
+}
 
-	idx := Index{
-		Entries:   s.entries,
-		UpdatedAt: time.Now(),
+// Add adds an entry to the index, unless one with its key is already there.
+func (s *Store) Add(entry IndexEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mutate(walRecord{Op: opAdd, Entry: &entry}) > 0 {
+		s.version++
 	}
+}
 
-	data, err := json.Marshal(idx)
-	if err != nil {
-		return fmt.Errorf("marshal index: %w", err)
+// Update replaces the indexed entry with the same key, reporting false if
+// there is none. The whole entry is replaced, link status included.
+func (s *Store) Update(entry IndexEntry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mutate(walRecord{Op: opUpdate, Entry: &entry}) == 0 {
+		return false
 	}
+	s.version++
+	return true
+}
 
-	if err := os.WriteFile(s.path, data, 0644); err != nil {
-		return fmt.Errorf("write index file: %w", err)
+// Delete removes the entry with key (see EntryKey) and any imported
+// embedding for it, reporting false if there was neither.
+func (s *Store) Delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mutate(walRecord{Op: opDelete, Key: key}) == 0 {
+		return false
 	}
+	s.version++
+	return true
+}
 
-	return nil
+// Clear removes all entries and sync state from the index.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mutate(walRecord{Op: opClear})
+	s.version++
+}
+
+// ClearUser removes all entries owned by userID and forgets its sync state.
+func (s *Store) ClearUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mutate(walRecord{Op: opClearUser, UserID: userID})
+	s.version++
 }
 
-// Has returns true if a bookmark ID is already indexed.
-func (s *Store) Has(id int) bool {
+// SourceCounts returns the number of indexed entries per source.
+func (s *Store) SourceCounts() map[string]int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.idSet[id]
+	counts := make(map[string]int)
+	for _, e := range s.entries {
+		counts[e.SourceName()]++
+	}
+	return counts
 }
 
-// Add adds an entry to the index.
-func (s *Store) Add(entry IndexEntry) {
+// ClaimUnowned assigns entries without an owner to userID. Indexes written
+// before multi-user support have no owner on their entries.
+func (s *Store) ClaimUnowned(userID string) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.entries = append(s.entries, entry)
-	s.idSet[entry.ID] = true
+
+	claimed := s.mutate(walRecord{Op: opClaim, UserID: userID})
+	if claimed > 0 {
+		s.version++
+	}
+	return claimed
 }
 
-// Clear removes all entries from the index.
-func (s *Store) Clear() {
+// UserState returns the sync state for userID.
+func (s *Store) UserState(userID string) UserState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.users[userID]
+}
+
+// SetUserState records the sync state for userID.
+func (s *Store) SetUserState(userID string, state UserState) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.entries = nil
-	s.idSet = make(map[int]bool)
+	s.mutate(walRecord{Op: opUser, UserID: userID, User: &state})
+}
+
+// SyncedUsers returns the users with recorded sync state, sorted.
+func (s *Store) SyncedUsers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// UserCounts returns the number of indexed entries per owner.
+func (s *Store) UserCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := make(map[string]int)
+	for _, e := range s.entries {
+		counts[e.UserID]++
+	}
+	return counts
+}
+
+// Version returns a counter that changes whenever the entries change.
+// Derived data (duplicates, clusters, stats) is cached against it.
+func (s *Store) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// SaveCopy writes the whole index to path, outside the data dir's log, and
+// returns the number of entries and the model it holds.
+func (s *Store) SaveCopy(path string) (int, string, error) {
+	s.mu.RLock()
+	count, model := len(s.entries), s.model
+	data, err := json.Marshal(Index{
+		Entries:     s.entries,
+		Users:       s.users,
+		Model:       s.model,
+		Precomputed: s.precomputed,
+		UpdatedAt:   time.Now(),
+	})
+	s.mu.RUnlock()
+	if err != nil {
+		return 0, "", fmt.Errorf("marshal index: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return 0, "", err
+	}
+	return count, model, nil
+}
+
+// Restore replaces the index with the copy at path, written by SaveCopy,
+// and compacts it so the log of the replaced index is dropped.
+func (s *Store) Restore(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read index copy: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return fmt.Errorf("decode index copy: %w", err)
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	s.mu.Lock()
+	// Keep numbering after the replaced index so none of its log is replayed
+	idx.Seq = s.seq + 1
+	s.reset(idx)
+	s.version++
+	s.mu.Unlock()
+
+	return s.compactLocked()
 }
 
 // Count returns the number of indexed entries.
@@ -111,40 +420,215 @@ func (s *Store) Count() int {
 	return len(s.entries)
 }
 
+// Model returns the embedding model the index was built with, or "" if unknown.
+func (s *Store) Model() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.model
+}
+
+// SetModel records the embedding model used for new entries.
+func (s *Store) SetModel(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mutate(walRecord{Op: opModel, Model: model})
+}
+
+// DiskSize returns the size of the index file and its log in bytes, or 0
+// if it hasn't been saved.
+func (s *Store) DiskSize() int64 {
+	var size int64
+	for _, path := range []string{s.path, s.walPath} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
 // UpdatedAt returns the last update time from disk, or zero time if unknown.
 func (s *Store) UpdatedAt() time.Time {
-	info, err := os.Stat(s.path)
-	if err != nil {
-		return time.Time{}
+	var updated time.Time
+	for _, path := range []string{s.path, s.walPath} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(updated) {
+			updated = info.ModTime()
+		}
+	}
+	return updated
+}
+
+// Get returns a copy of the entry with key (see EntryKey).
+func (s *Store) Get(key string) (IndexEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.byKey[key]
+	if !ok {
+		return IndexEntry{}, false
+	}
+	return s.entries[i], true
+}
+
+// Bookmark lookup errors.
+var (
+	ErrNotFound  = errors.New("bookmark not found")
+	ErrAmbiguous = errors.New("bookmark ID is ambiguous")
+)
+
+// Lookup finds the bookmark with id, narrowed to source and userID when
+// they are not empty. IDs are only unique per source and user, so it fails
+// with ErrAmbiguous if several bookmarks match.
+func (s *Store) Lookup(source, userID string, id int) (IndexEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []IndexEntry
+	for _, e := range s.entries {
+		if e.ID == id && (source == "" || e.SourceName() == source) && (userID == "" || e.UserID == userID) {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return IndexEntry{}, fmt.Errorf("bookmark %d: %w", id, ErrNotFound)
+	case 1:
+		return found[0], nil
 	}
-	return info.ModTime()
+	keys := make([]string, len(found))
+	for i, e := range found {
+		keys[i] = e.Key()
+	}
+	return IndexEntry{}, fmt.Errorf("%w: %d matches %s; pass its key, user or source", ErrAmbiguous, id, strings.Join(keys, ", "))
 }
 
-// GetByID returns the entry with the given ID, or nil if not found.
-func (s *Store) GetByID(id int) *IndexEntry {
+// Entries returns a snapshot of all entries. The slice is a copy; entry
+// fields (embeddings, tags) are shared and must not be modified.
+func (s *Store) Entries() []IndexEntry {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	for i := range s.entries {
-		if s.entries[i].ID == id {
-			return &s.entries[i]
+	out := make([]IndexEntry, len(s.entries))
+	copy(out, s.entries)
+	return out
+}
+
+// eachBatch is how many entries Each copies per hold of the read lock.
+const eachBatch = 256
+
+// Each calls fn for every entry matching filter, in index order, and stops
+// at the first error. Only the matching keys are taken up front; entries
+// are copied a batch at a time, so fn can be slow (say, writing to a
+// client) without blocking the indexer or copying the whole index.
+// Embeddings are shared. Entries deleted meanwhile are skipped, and entries
+// added meanwhile are not visited.
+func (s *Store) Each(filter Filter, fn func(IndexEntry) error) error {
+	s.mu.RLock()
+	var keys []string
+	for _, e := range s.entries {
+		if filter.Match(e) {
+			keys = append(keys, e.Key())
+		}
+	}
+	s.mu.RUnlock()
+
+	batch := make([]IndexEntry, 0, min(len(keys), eachBatch))
+	for len(keys) > 0 {
+		n := min(len(keys), eachBatch)
+		batch = batch[:0]
+		s.mu.RLock()
+		for _, key := range keys[:n] {
+			if i, ok := s.byKey[key]; ok && filter.Match(s.entries[i]) {
+				batch = append(batch, s.entries[i])
+			}
+		}
+		s.mu.RUnlock()
+		keys = keys[n:]
+
+		for _, e := range batch {
+			if err := fn(e); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// SetLinkStatus records a liveness check result on the entry with the given key.
+// It returns false if the entry no longer exists.
+func (s *Store) SetLinkStatus(key string, status LinkStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mutate(walRecord{Op: opLink, Key: key, LinkStatus: &status}) == 0 {
+		return false
+	}
+	s.version++
+	return true
+}
+
+// List returns entries matching filter, newest first, without scoring.
+func (s *Store) List(filter Filter, limit int) []SearchResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []SearchResult
+	for _, entry := range s.entries {
+		if filter.Match(entry) {
+			results = append(results, SearchResult{Entry: entry})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Entry.CreatedAt.After(results[j].Entry.CreatedAt)
+	})
+
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// Recent returns entries matching filter, most recently indexed first.
+// Entries indexed before IndexedAt was recorded sort by their save date.
+func (s *Store) Recent(filter Filter, limit int) []SearchResult {
+	results := s.List(filter, 0)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return indexedAt(results[i].Entry).After(indexedAt(results[j].Entry))
+	})
+
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func indexedAt(e IndexEntry) time.Time {
+	if e.IndexedAt.IsZero() {
+		return e.CreatedAt
+	}
+	return e.IndexedAt
+}
+
 // SearchResult is a scored index entry from a search.
 type SearchResult struct {
 	Entry IndexEntry
 	Score float32
 }
 
-const (
-	semanticWeight = 0.7
-	keywordWeight  = 0.3
-)
+// DefaultSemanticWeight is the share of a hybrid search score taken by
+// cosine similarity; keyword matching gets the rest.
+const DefaultSemanticWeight = 0.7
+
+// SetSemanticWeight sets the share (0-1) of the hybrid score given to
+// cosine similarity.
+func (s *Store) SetSemanticWeight(w float32) {
+	s.mu.Lock()
+	s.semanticWeight = w
+	s.mu.Unlock()
+}
 
-// Search finds the top-k entries using hybrid scoring (cosine similarity + keyword match).
-func (s *Store) Search(queryVec []float32, query string, limit int) []SearchResult {
+// Search finds the top-k entries matching filter using hybrid scoring (cosine similarity + keyword match).
+func (s *Store) Search(queryVec []float32, query string, limit int, filter Filter) []SearchResult {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -153,12 +637,16 @@ func (s *Store) Search(queryVec []float32, query string, limit int) []SearchResu
 	}
 
 	queryTerms := tokenize(query)
+	semanticWeight, keywordWeight := s.semanticWeight, 1-s.semanticWeight
 
 	results := make([]SearchResult, 0, len(s.entries))
 	for _, entry := range s.entries {
-		cosine := cosineSimilarity(queryVec, entry.Embedding)
+		if !filter.Match(entry) {
+			continue
+		}
+		cosine := CosineSimilarity(queryVec, entry.Embedding)
 		keyword := keywordScore(entry, queryTerms)
-		score := float32(semanticWeight)*cosine + float32(keywordWeight)*keyword
+		score := semanticWeight*cosine + keywordWeight*keyword
 		results = append(results, SearchResult{Entry: entry, Score: score})
 	}
 
@@ -173,8 +661,9 @@ func (s *Store) Search(queryVec []float32, query string, limit int) []SearchResu
 	return results
 }
 
-// SearchByVector finds the top-k entries most similar to a vector (no keyword component).
-func (s *Store) SearchByVector(queryVec []float32, limit int, excludeID int) []SearchResult {
+// SearchByVector finds the top-k entries most similar to a vector (no
+// keyword component), leaving out the entry with excludeKey.
+func (s *Store) SearchByVector(queryVec []float32, limit int, excludeKey string) []SearchResult {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -182,12 +671,16 @@ func (s *Store) SearchByVector(queryVec []float32, limit int, excludeID int) []S
 		return nil
 	}
 
+	exclude, ok := s.byKey[excludeKey]
+	if !ok {
+		exclude = -1
+	}
 	results := make([]SearchResult, 0, len(s.entries))
-	for _, entry := range s.entries {
-		if entry.ID == excludeID {
+	for i, entry := range s.entries {
+		if i == exclude {
 			continue
 		}
-		score := cosineSimilarity(queryVec, entry.Embedding)
+		score := CosineSimilarity(queryVec, entry.Embedding)
 		results = append(results, SearchResult{Entry: entry, Score: score})
 	}
 
@@ -233,7 +726,7 @@ func tokenize(s string) []string {
 }
 
 // BuildEmbeddingText creates the text to embed for a bookmark.
-func BuildEmbeddingText(link curius.Link) string {
+func BuildEmbeddingText(link source.Link) string {
 	var b strings.Builder
 
 	b.WriteString(link.Title)
@@ -252,7 +745,7 @@ func BuildEmbeddingText(link curius.Link) string {
 	}
 
 	for _, t := range link.Tags {
-		b.WriteString(t.Name)
+		b.WriteString(t)
 		b.WriteString(" ")
 	}
 	if len(link.Tags) > 0 {
@@ -261,23 +754,3 @@ func BuildEmbeddingText(link curius.Link) string {
 
 	return b.String()
 }
-
-func cosineSimilarity(a, b []float32) float32 {
-	if len(a) != len(b) || len(a) == 0 {
-		return 0
-	}
-
-	var dot, normA, normB float64
-	for i := range a {
-		dot += float64(a[i]) * float64(b[i])
-		normA += float64(a[i]) * float64(a[i])
-		normB += float64(b[i]) * float64(b[i])
-	}
-
-	denom := math.Sqrt(normA) * math.Sqrt(normB)
-	if denom == 0 {
-		return 0
-	}
-
-	return float32(dot / denom)
-}
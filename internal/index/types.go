@@ -1,21 +1,88 @@
 package index
 
-import "time"
+import (
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/source"
+)
 
 // IndexEntry stores a bookmark with its embedding vector.
 type IndexEntry struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	URL         string    `json:"url"`
-	Highlights  []string  `json:"highlights,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"createdAt"`
-	Embedding   []float32 `json:"embedding"`
+	ID          int         `json:"id"`
+	Source      string      `json:"source,omitempty"`
+	UserID      string      `json:"userId,omitempty"`
+	Network     bool        `json:"network,omitempty"`
+	Title       string      `json:"title"`
+	URL         string      `json:"url"`
+	Highlights  []string    `json:"highlights,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Description string      `json:"description,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	IndexedAt   time.Time   `json:"indexedAt,omitempty"`
+	Embedding   []float32   `json:"embedding"`
+	LinkStatus  *LinkStatus `json:"linkStatus,omitempty"`
+}
+
+// LinkStatus is the result of the last liveness check of a bookmark's URL.
+type LinkStatus struct {
+	StatusCode int       `json:"statusCode,omitempty"`
+	FinalURL   string    `json:"finalUrl,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	Error      string    `json:"error,omitempty"`
+	Dead       bool      `json:"dead"`
+	// Failures counts consecutive checks that failed below HTTP (refused
+	// connections, timeouts, TLS errors) or with a server error. Until a
+	// server error persists the link is neither dead nor alive and is retried.
+	Failures int `json:"failures,omitempty"`
+}
+
+// IsDead reports whether the last check found the URL broken.
+func (e IndexEntry) IsDead() bool {
+	return e.LinkStatus != nil && e.LinkStatus.Dead
+}
+
+// Key returns the identity of the entry within the store. The same Curius
+// link can be saved by several users, and IDs are only unique within a
+// source, so both are part of the key.
+func (e IndexEntry) Key() string {
+	return EntryKey(e.SourceName(), e.UserID, e.ID)
+}
+
+// SourceName returns where the entry was imported from. Entries indexed
+// before sources were recorded all came from Curius.
+func (e IndexEntry) SourceName() string {
+	if e.Source == "" {
+		return source.NameCurius
+	}
+	return e.Source
+}
+
+// UserState records the incremental sync state for a single Curius user.
+type UserState struct {
+	LastSyncAt time.Time `json:"lastSyncAt"`
+	LinkCount  int       `json:"linkCount"`
+	LastError  string    `json:"lastError,omitempty"`
+	Network    bool      `json:"network,omitempty"`
+}
+
+// PrecomputedVector is an embedding imported from another index for a
+// bookmark not indexed yet. Indexing the bookmark uses it instead of
+// embedding it again, if the model still matches.
+type PrecomputedVector struct {
+	UserID    string    `json:"userId,omitempty"`
+	Model     string    `json:"model"`
+	Embedding []float32 `json:"embedding"`
 }
 
 // Index is the top-level persisted structure.
 type Index struct {
-	Entries   []IndexEntry `json:"entries"`
-	UpdatedAt time.Time    `json:"updatedAt"`
+	Entries []IndexEntry         `json:"entries"`
+	Users   map[string]UserState `json:"users,omitempty"`
+	// Model is the embedding model the entries were embedded with.
+	Model string `json:"model,omitempty"`
+	// Precomputed holds imported embeddings by entry key.
+	Precomputed map[string]PrecomputedVector `json:"precomputed,omitempty"`
+	// Seq is the sequence number of the last logged mutation included.
+	Seq       uint64    `json:"seq,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,85 @@
+package index
+
+import "math"
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0
+// if they differ in length or either is zero.
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	denom := math.Sqrt(normA) * math.Sqrt(normB)
+	if denom == 0 {
+		return 0
+	}
+
+	return float32(dot / denom)
+}
+
+// Dot returns the dot product of a and b, which must be the same length.
+// For unit vectors it is their cosine similarity.
+func Dot(a, b []float32) float32 {
+	var s float32
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+// Normalize returns a unit-length copy of v, or nil for a zero vector.
+func Normalize(v []float32) []float32 {
+	out := append([]float32(nil), v...)
+	if !NormalizeInPlace(out) {
+		return nil
+	}
+	return out
+}
+
+// NormalizeInPlace scales v to unit length, reporting false if v is zero.
+func NormalizeInPlace(v []float32) bool {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return false
+	}
+	inv := float32(1 / math.Sqrt(sum))
+	for i := range v {
+		v[i] *= inv
+	}
+	return true
+}
+
+// WithCommonDim returns the entries whose embedding has the most common
+// size among entries, so vectors of a previous model are left out.
+func WithCommonDim(entries []IndexEntry) []IndexEntry {
+	counts := make(map[int]int)
+	for _, e := range entries {
+		if len(e.Embedding) > 0 {
+			counts[len(e.Embedding)]++
+		}
+	}
+	dim, best := 0, 0
+	for d, c := range counts {
+		if c > best || c == best && d > dim {
+			dim, best = d, c
+		}
+	}
+
+	out := make([]IndexEntry, 0, best)
+	for _, e := range entries {
+		if len(e.Embedding) == dim {
+			out = append(out, e)
+		}
+	}
+	return out
+}
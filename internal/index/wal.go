@@ -0,0 +1,425 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Store mutations are appended to a write-ahead log next to the index
+// file, so saving costs O(changes). The index file is a snapshot that
+// records the sequence number of the last mutation it includes; loading
+// replays the later log records onto it. Compaction writes a new snapshot
+// and drops the log.
+
+const (
+	opAdd       = "add"
+	opUpdate    = "update"
+	opDelete    = "delete"
+	opClear     = "clear"
+	opClearUser = "clearUser"
+	opClaim     = "claim"
+	opUser      = "user"
+	opModel     = "model"
+	opLink      = "link"
+	opVector    = "vector"
+)
+
+// minCompactBytes is the log size below which saves never compact.
+const minCompactBytes = 1 << 20
+
+// walRecord is one logged mutation.
+type walRecord struct {
+	Seq        uint64             `json:"seq"`
+	Op         string             `json:"op"`
+	Entry      *IndexEntry        `json:"entry,omitempty"`
+	Key        string             `json:"key,omitempty"`
+	UserID     string             `json:"userId,omitempty"`
+	User       *UserState         `json:"user,omitempty"`
+	Model      string             `json:"model,omitempty"`
+	LinkStatus *LinkStatus        `json:"linkStatus,omitempty"`
+	Vector     *PrecomputedVector `json:"vector,omitempty"`
+}
+
+// mutate applies r and queues it for the log. It returns how many
+// entries or users r changed; no-ops are not logged. Callers hold s.mu.
+func (s *Store) mutate(r walRecord) int {
+	n := s.apply(r)
+	if n == 0 {
+		return 0
+	}
+	s.seq++
+	r.Seq = s.seq
+	s.pending = append(s.pending, r)
+	return n
+}
+
+// apply performs the mutation r describes. Callers hold s.mu.
+func (s *Store) apply(r walRecord) int {
+	switch r.Op {
+	case opAdd:
+		if r.Entry == nil {
+			return 0
+		}
+		key := r.Entry.Key()
+		if _, ok := s.byKey[key]; ok {
+			return 0
+		}
+		s.byKey[key] = len(s.entries)
+		s.entries = append(s.entries, *r.Entry)
+		delete(s.precomputed, key)
+		return 1
+
+	case opUpdate:
+		if r.Entry == nil {
+			return 0
+		}
+		if i, ok := s.byKey[r.Entry.Key()]; ok {
+			s.entries[i] = *r.Entry
+			return 1
+		}
+
+	case opDelete:
+		removed := 0
+		if _, ok := s.precomputed[r.Key]; ok {
+			delete(s.precomputed, r.Key)
+			removed++
+		}
+		if i, ok := s.byKey[r.Key]; ok {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			s.reindex()
+			removed++
+		}
+		return removed
+
+	case opClear:
+		n := len(s.entries) + len(s.users) + len(s.precomputed)
+		s.entries = nil
+		s.byKey = make(map[string]int)
+		s.users = make(map[string]UserState)
+		s.precomputed = make(map[string]PrecomputedVector)
+		return max(n, 1)
+
+	case opClearUser:
+		removed := 0
+		kept := s.entries[:0]
+		for _, e := range s.entries {
+			if e.UserID == r.UserID {
+				removed++
+				continue
+			}
+			kept = append(kept, e)
+		}
+		s.entries = kept
+		s.reindex()
+		if _, ok := s.users[r.UserID]; ok {
+			delete(s.users, r.UserID)
+			removed++
+		}
+		for key, v := range s.precomputed {
+			if v.UserID == r.UserID {
+				delete(s.precomputed, key)
+				removed++
+			}
+		}
+		return removed
+
+	case opClaim:
+		claimed := 0
+		for i := range s.entries {
+			if s.entries[i].UserID != "" {
+				continue
+			}
+			s.entries[i].UserID = r.UserID
+			claimed++
+		}
+		if claimed > 0 {
+			s.reindex()
+		}
+		return claimed
+
+	case opUser:
+		if r.User == nil {
+			return 0
+		}
+		s.users[r.UserID] = *r.User
+		return 1
+
+	case opModel:
+		if s.model == r.Model {
+			return 0
+		}
+		s.model = r.Model
+		return 1
+
+	case opLink:
+		if r.LinkStatus == nil {
+			return 0
+		}
+		if i, ok := s.byKey[r.Key]; ok {
+			status := *r.LinkStatus
+			s.entries[i].LinkStatus = &status
+			return 1
+		}
+
+	case opVector:
+		if r.Vector == nil || s.has(r.Key) {
+			return 0
+		}
+		s.precomputed[r.Key] = *r.Vector
+		return 1
+	}
+	return 0
+}
+
+// Checkpoint appends the pending mutations to the log and syncs it, so a
+// long index run that crashes keeps its progress.
+func (s *Store) Checkpoint() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	return s.flushLocked()
+}
+
+// flushLocked appends pending records to the log. Callers hold s.saveMu.
+func (s *Store) flushLocked() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	n, err := appendLog(s.walPath, pending, s.walTorn)
+	if err != nil {
+		// Keep the records for the next attempt
+		s.mu.Lock()
+		s.pending = append(pending, s.pending...)
+		s.mu.Unlock()
+		return err
+	}
+	s.walBytes += n
+	s.walTorn = false
+	return nil
+}
+
+// appendLog writes records to the log as JSON lines, syncs it, and
+// returns the number of bytes written. If torn is set the log ends in a
+// partial line, which is terminated first so it stays on a line of its own.
+func appendLog(path string, records []walRecord, torn bool) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("create data dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("open index log: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	var written int64
+	if torn {
+		w.WriteByte('\n')
+		written++
+	}
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return 0, fmt.Errorf("encode index log record: %w", err)
+		}
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return 0, fmt.Errorf("write index log: %w", err)
+		}
+		written += int64(len(line))
+	}
+	if err := w.Flush(); err != nil {
+		return 0, fmt.Errorf("write index log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("sync index log: %w", err)
+	}
+	return written, nil
+}
+
+// Recovered returns how many logged mutations the last LoadFromDisk
+// replayed onto the index file.
+func (s *Store) Recovered() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recovered
+}
+
+// replayLog applies the log records newer than the loaded snapshot. Lines
+// without an op, from logs of new entries only, are adds. Loading never
+// writes: an unterminated last line (torn by a crash, or still being
+// written by another process) is ignored, and an unreadable line is
+// skipped with a warning rather than dropping the records after it. Both
+// are dropped by the next compaction. Callers hold s.mu.
+func (s *Store) replayLog(snapshotSeq uint64) error {
+	s.recovered = 0
+	s.walTorn = false
+	f, err := os.Open(s.walPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open index log: %w", err)
+	}
+	defer f.Close()
+
+	var size int64
+	br := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		size += int64(len(line))
+		if errors.Is(err, io.EOF) {
+			s.walTorn = len(line) > 0
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read index log: %w", err)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		r, ok := decodeRecord(line)
+		if !ok {
+			log.Printf("Warning: skipping unreadable line %d of %s", n, s.walPath)
+			continue
+		}
+
+		// Unnumbered records predate numbered snapshots
+		if r.Seq <= snapshotSeq && (r.Seq != 0 || snapshotSeq != 0) {
+			continue
+		}
+		if s.apply(r) > 0 {
+			s.recovered++
+		}
+		s.seq = max(s.seq, r.Seq)
+	}
+
+	s.walBytes = size
+	return nil
+}
+
+// decodeRecord parses one log line, reporting false if it is unreadable.
+func decodeRecord(line []byte) (walRecord, bool) {
+	var r walRecord
+	if err := json.Unmarshal(line, &r); err != nil {
+		return r, false
+	}
+	if r.Op == "" {
+		var e IndexEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return r, false
+		}
+		r = walRecord{Op: opAdd, Entry: &e}
+	}
+	return r, true
+}
+
+// Compact writes a snapshot of the whole index and removes the log.
+func (s *Store) Compact() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	if err := s.flushLocked(); err != nil {
+		return err
+	}
+	return s.compactLocked()
+}
+
+// compactLocked writes the snapshot. Records queued while it is written
+// have later sequence numbers and go to the next log. Callers hold s.saveMu.
+func (s *Store) compactLocked() error {
+	s.mu.RLock()
+	idx := Index{
+		Entries:     s.entries,
+		Users:       s.users,
+		Model:       s.model,
+		Precomputed: s.precomputed,
+		Seq:         s.seq,
+		UpdatedAt:   time.Now(),
+	}
+	data, err := json.Marshal(idx)
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	s.snapshotBytes = int64(len(data))
+
+	// Every logged record is now durably in the snapshot
+	if err := os.Remove(s.walPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove index log: %w", err)
+	}
+	s.walBytes = 0
+	s.walTorn = false
+	return nil
+}
+
+// writeFileAtomic replaces the index file at path with data, so readers
+// and crashes never see a partial file. The data and the rename are synced
+// before it returns, so the log the file replaces can then be removed.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("write index file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write index file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync index file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write index file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace index file: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable. Windows can't sync directories
+// and doesn't need to.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// parkingHosts are domain-parking and for-sale services that expired
+// domains commonly redirect to. A redirect to one of them counts as dead.
+var parkingHosts = []string{
+	"sedoparking.com",
+	"sedo.com",
+	"parkingcrew.net",
+	"bodis.com",
+	"hugedomains.com",
+	"dan.com",
+	"afternic.com",
+	"godaddy.com",
+	"domainmarket.com",
+	"undeveloped.com",
+}
+
+const userAgent = "curius-search-linkcheck/1.0"
+
+// probeHost is resolved to tell a domain that no longer exists from a
+// machine that is offline or has a broken resolver.
+const probeHost = "example.com"
+
+// serverErrorChecks is how many consecutive failed checks ending in a
+// server error (other than 503) it takes to count a link as dead.
+const serverErrorChecks = 3
+
+// retryBase is the delay before re-checking a link whose last check failed
+// with a network or server error; it doubles with each consecutive failure, up to MaxAge.
+const retryBase = time.Hour
+
+// Options tunes a Checker.
+type Options struct {
+	// HostDelay is the minimum time between requests to the same host.
+	HostDelay time.Duration
+	// MaxAge is how long a check result stays fresh before it is re-checked.
+	MaxAge time.Duration
+	// Workers is the number of concurrent checks across different hosts.
+	Workers int
+}
+
+// Checker issues HEAD/GET requests for indexed bookmarks and records the
+// outcome on each entry.
+type Checker struct {
+	store      *index.Store
+	opts       Options
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	nextSlot map[string]time.Time
+	probedAt time.Time
+	probeOK  bool
+}
+
+func NewChecker(store *index.Store, opts Options) *Checker {
+	if opts.Workers <= 0 {
+		opts.Workers = 8
+	}
+	return &Checker{
+		store: store,
+		opts:  opts,
+		httpClient: &http.Client{
+			Timeout: 20 * time.Second,
+		},
+		nextSlot: make(map[string]time.Time),
+	}
+}
+
+// Run checks every entry whose last check is older than MaxAge, then saves
+// the index. It returns early when ctx is cancelled.
+func (c *Checker) Run(ctx context.Context) {
+	var due []index.IndexEntry
+	for _, e := range c.store.Entries() {
+		if c.isDue(e.LinkStatus) {
+			due = append(due, e)
+		}
+	}
+
+	if len(due) == 0 {
+		return
+	}
+	if !c.online(ctx) {
+		log.Printf("Link check: cannot resolve %s, network unavailable; skipping %d bookmarks", probeHost, len(due))
+		return
+	}
+	log.Printf("Link check: checking %d bookmarks", len(due))
+
+	jobs := make(chan index.IndexEntry)
+	var wg sync.WaitGroup
+	var countMu sync.Mutex
+	dead, skipped := 0, 0
+
+	for i := 0; i < c.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				status, ok := c.Check(ctx, e.URL)
+				if ctx.Err() != nil {
+					return
+				}
+				if !ok {
+					countMu.Lock()
+					skipped++
+					countMu.Unlock()
+					continue
+				}
+				if prev := e.LinkStatus; status.Failures > 0 && prev != nil {
+					status.Failures += prev.Failures
+				}
+				status.Dead = status.Dead || confirmedServerError(status)
+				c.store.SetLinkStatus(e.Key(), status)
+				if status.Dead {
+					countMu.Lock()
+					dead++
+					countMu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, e := range due {
+		select {
+		case jobs <- e:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := c.store.SaveToDisk(); err != nil {
+		log.Printf("Link check: error saving index: %v", err)
+		return
+	}
+	log.Printf("Link check: done, %d dead links found", dead)
+	if skipped > 0 {
+		log.Printf("Link check: %d bookmarks left unchecked while the network was unavailable", skipped)
+	}
+}
+
+// isDue reports whether a link with the given last result needs checking:
+// never checked, older than MaxAge, or failed and its backoff has passed.
+func (c *Checker) isDue(ls *index.LinkStatus) bool {
+	if ls == nil {
+		return true
+	}
+	age := time.Since(ls.CheckedAt)
+	if ls.Failures > 0 {
+		retry := retryBase << min(ls.Failures-1, 16)
+		return age > min(retry, c.opts.MaxAge)
+	}
+	return age > c.opts.MaxAge
+}
+
+// confirmedServerError reports whether status is a server error that has
+// persisted over serverErrorChecks checks. 503 usually means maintenance or
+// rate limiting, so it is never confirmed.
+func confirmedServerError(status index.LinkStatus) bool {
+	return status.StatusCode >= 500 && status.StatusCode != http.StatusServiceUnavailable &&
+		status.Failures >= serverErrorChecks
+}
+
+// online reports whether the resolver can resolve probeHost, caching the
+// answer for a minute.
+func (c *Checker) online(ctx context.Context) bool {
+	c.mu.Lock()
+	if time.Since(c.probedAt) < time.Minute {
+		ok := c.probeOK
+		c.mu.Unlock()
+		return ok
+	}
+	c.mu.Unlock()
+
+	_, err := net.DefaultResolver.LookupHost(ctx, probeHost)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probedAt, c.probeOK = time.Now(), err == nil
+	return c.probeOK
+}
+
+// Start runs the checker now and then every interval until ctx is cancelled.
+func (c *Checker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			c.Run(ctx)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Check requests rawURL and classifies the response. HEAD is tried first;
+// servers that reject it are retried with GET. Only HTTP 404 and 410,
+// parked redirects and domains that don't resolve while the resolver works
+// count as dead; server errors and other network errors are recorded as a
+// failure and retried. ok is false when nothing can be said because the
+// check was cancelled or the network is down.
+func (c *Checker) Check(ctx context.Context, rawURL string) (status index.LinkStatus, ok bool) {
+	status = index.LinkStatus{CheckedAt: time.Now()}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		status.Error = "invalid url"
+		status.Dead = true
+		return status, true
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, u)
+	if err == nil && retryWithGet(resp.StatusCode) {
+		resp.Body.Close()
+		resp, err = c.do(ctx, http.MethodGet, u)
+	}
+	if err != nil {
+		if ctx.Err() != nil || !c.online(ctx) {
+			return status, false
+		}
+		status.Error = err.Error()
+		if isNXDomain(err) {
+			status.Dead = true
+		} else {
+			status.Failures = 1
+		}
+		return status, true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	status.StatusCode = resp.StatusCode
+	if final := resp.Request.URL.String(); final != rawURL {
+		status.FinalURL = final
+	}
+	// Server errors are often transient: retry them like network failures
+	// and let Run confirm them over several checks
+	if resp.StatusCode >= 500 {
+		status.Failures = 1
+		return status, true
+	}
+	status.Dead = resp.StatusCode == http.StatusNotFound ||
+		resp.StatusCode == http.StatusGone ||
+		isParked(u.Hostname(), resp.Request.URL.Hostname())
+
+	return status, true
+}
+
+func (c *Checker) do(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	if err := c.wait(ctx, u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	return c.httpClient.Do(req)
+}
+
+// wait blocks until host may be requested again, reserving the next slot.
+func (c *Checker) wait(ctx context.Context, host string) error {
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextSlot[host] = slot.Add(c.opts.HostDelay)
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(slot)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryWithGet reports whether a HEAD response code may just mean the
+// server doesn't support HEAD.
+func retryWithGet(code int) bool {
+	switch code {
+	case http.StatusMethodNotAllowed, http.StatusForbidden, http.StatusNotImplemented, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+// isNXDomain reports whether err says the host name does not exist.
+func isNXDomain(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// isParked reports whether a redirect from origHost landed on a parking service.
+func isParked(origHost, finalHost string) bool {
+	for _, p := range parkingHosts {
+		if withinDomain(finalHost, p) && !withinDomain(origHost, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinDomain(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// WaybackURL returns the Internet Archive URL for rawURL, resolving to the
+// snapshot closest to when the bookmark was saved.
+func WaybackURL(rawURL string, savedAt time.Time) string {
+	ts := "2"
+	if !savedAt.IsZero() {
+		ts = savedAt.UTC().Format("20060102150405")
+	}
+	return "https://web.archive.org/web/" + ts + "/" + rawURL
+}
@@ -0,0 +1,164 @@
+package projection
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+const (
+	layoutNeighbours = 10
+	layoutEpochs     = 200
+	negativeSamples  = 5
+	// UMAP curve parameters for min_dist = 0.1
+	curveA = 1.577
+	curveB = 0.895
+)
+
+// layout refines an initial 2D embedding with a UMAP-style optimisation:
+// points are pulled towards their nearest neighbours in embedding space and
+// pushed away from random other points. vecs must be unit length.
+func layout(vecs [][]float32, init [][]float64) [][]float64 {
+	n := len(vecs)
+	if n < 3 {
+		return init
+	}
+	k := min(layoutNeighbours, n-1)
+	graph := knnGraph(vecs, k)
+
+	pos := make([][]float64, n)
+	scaleToUnit(init)
+	for i := range init {
+		pos[i] = []float64{init[i][0] * 10, init[i][1] * 10}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for epoch := 0; epoch < layoutEpochs; epoch++ {
+		alpha := 1 - float64(epoch)/layoutEpochs
+		for i, edges := range graph {
+			for _, e := range edges {
+				j := e.j
+				d2 := sqDist(pos[i], pos[j])
+				if d2 > 0 {
+					// Attraction along a neighbour edge
+					pb := math.Pow(d2, curveB)
+					coeff := -2 * curveA * curveB * (pb / d2) / (1 + curveA*pb)
+					move(pos[i], pos[j], coeff*e.w*alpha)
+				}
+
+				for s := 0; s < negativeSamples; s++ {
+					r := rng.Intn(n)
+					if r == i {
+						continue
+					}
+					d2 := sqDist(pos[i], pos[r])
+					// Repulsion from a random point
+					coeff := 2 * curveB / ((0.001 + d2) * (1 + curveA*math.Pow(d2, curveB)))
+					move(pos[i], pos[r], coeff*alpha)
+				}
+			}
+		}
+	}
+
+	return pos
+}
+
+type edge struct {
+	j int
+	w float64
+}
+
+// knnGraph returns each point's k nearest neighbours by cosine distance,
+// weighted by an exponential of the distance scaled to the point's
+// neighbourhood, as in UMAP's fuzzy simplicial set.
+func knnGraph(x [][]float32, k int) [][]edge {
+	n := len(x)
+	graph := make([][]edge, n)
+
+	workers := runtime.NumCPU()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// nearest is kept sorted by distance; only k slots are needed
+			nearest := make([]edge, 0, k+1)
+			for i := w; i < n; i += workers {
+				nearest = nearest[:0]
+				for j := 0; j < n; j++ {
+					if j == i {
+						continue
+					}
+					d := float64(1 - index.Dot(x[i], x[j]))
+					if len(nearest) == k && d >= nearest[k-1].w {
+						continue
+					}
+					pos := sort.Search(len(nearest), func(p int) bool { return nearest[p].w > d })
+					nearest = append(nearest, edge{})
+					copy(nearest[pos+1:], nearest[pos:])
+					nearest[pos] = edge{j: j, w: d}
+					if len(nearest) > k {
+						nearest = nearest[:k]
+					}
+				}
+
+				rho := nearest[0].w
+				sigma := nearest[len(nearest)-1].w - rho
+				if sigma <= 0 {
+					sigma = 1e-3
+				}
+				edges := make([]edge, len(nearest))
+				for e, c := range nearest {
+					edges[e] = edge{j: c.j, w: math.Exp(-(c.w - rho) / sigma)}
+				}
+				graph[i] = edges
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return graph
+}
+
+// move shifts p along (p - q) by coeff, clipping the step.
+func move(p, q []float64, coeff float64) {
+	for d := range p {
+		g := coeff * (p[d] - q[d])
+		g = math.Max(-4, math.Min(4, g))
+		p[d] += g
+	}
+}
+
+func sqDist(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		d := a[i] - b[i]
+		s += d * d
+	}
+	return s
+}
+
+// scaleToUnit rescales points in place so that each axis spans [-1, 1].
+func scaleToUnit(points [][]float64) {
+	if len(points) == 0 {
+		return
+	}
+	for d := range points[0] {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for _, p := range points {
+			lo = math.Min(lo, p[d])
+			hi = math.Max(hi, p[d])
+		}
+		span := hi - lo
+		if span == 0 {
+			span = 1
+		}
+		for _, p := range points {
+			p[d] = 2*(p[d]-lo)/span - 1
+		}
+	}
+}
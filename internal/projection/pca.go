@@ -0,0 +1,102 @@
+package projection
+
+import (
+	"math"
+	"math/rand"
+)
+
+// pca projects rows of x onto their top k principal components, using
+// power iteration with deflation. Rows are centred first; x is not modified.
+func pca(x [][]float32, k int) [][]float64 {
+	n := len(x)
+	if n == 0 {
+		return nil
+	}
+	dim := len(x[0])
+	k = min(k, dim)
+
+	mean := make([]float64, dim)
+	for _, row := range x {
+		for j, v := range row {
+			mean[j] += float64(v)
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(n)
+	}
+
+	centred := make([][]float64, n)
+	for i, row := range x {
+		c := make([]float64, dim)
+		for j, v := range row {
+			c[j] = float64(v) - mean[j]
+		}
+		centred[i] = c
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	components := make([][]float64, 0, k)
+	for c := 0; c < k; c++ {
+		v := make([]float64, dim)
+		for j := range v {
+			v[j] = rng.NormFloat64()
+		}
+		normalize64(v)
+
+		for iter := 0; iter < 100; iter++ {
+			// v' = Xᵀ(Xv), without forming the covariance matrix
+			next := make([]float64, dim)
+			for _, row := range centred {
+				p := dot64(row, v)
+				for j, x := range row {
+					next[j] += p * x
+				}
+			}
+			// Deflate: remove directions already found
+			for _, prev := range components {
+				p := dot64(next, prev)
+				for j := range next {
+					next[j] -= p * prev[j]
+				}
+			}
+			if normalize64(next) == 0 {
+				break
+			}
+			delta := 1 - math.Abs(dot64(next, v))
+			v = next
+			if delta < 1e-9 {
+				break
+			}
+		}
+		components = append(components, v)
+	}
+
+	out := make([][]float64, n)
+	for i, row := range centred {
+		p := make([]float64, len(components))
+		for c, comp := range components {
+			p[c] = dot64(row, comp)
+		}
+		out[i] = p
+	}
+	return out
+}
+
+func dot64(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func normalize64(v []float64) float64 {
+	norm := math.Sqrt(dot64(v, v))
+	if norm == 0 {
+		return 0
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return norm
+}
@@ -0,0 +1,139 @@
+package projection
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// Point is one bookmark's position on the map. Coordinates are in [-1, 1].
+type Point struct {
+	Key  string  `json:"key"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	PCAX float64 `json:"pcaX"`
+	PCAY float64 `json:"pcaY"`
+}
+
+// Map is the persisted projection of the library.
+type Map struct {
+	Points    []Point   `json:"points"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Service computes the 2D map after each index run and serves the cached result.
+type Service struct {
+	mu   sync.RWMutex
+	data Map
+	path string
+}
+
+func NewService(dataDir string) *Service {
+	return &Service{path: filepath.Join(dataDir, "map.json")}
+}
+
+// LoadFromDisk loads the last computed map. Returns nil if there is none.
+func (s *Service) LoadFromDisk() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read map file: %w", err)
+	}
+
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("decode map: %w", err)
+	}
+
+	s.mu.Lock()
+	s.data = m
+	s.mu.Unlock()
+	return nil
+}
+
+// Rebuild projects entries to 2D and persists the result.
+func (s *Service) Rebuild(entries []index.IndexEntry) error {
+	m := Build(entries)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal map: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("write map file: %w", err)
+	}
+
+	s.mu.Lock()
+	s.data = m
+	s.mu.Unlock()
+	return nil
+}
+
+// Map returns the cached projection.
+func (s *Service) Map() Map {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+// Build projects entries' embeddings to 2D twice: with PCA, and with a
+// UMAP-style neighbour-preserving layout initialised from PCA. Entries whose
+// embedding size differs from the majority are left out.
+func Build(entries []index.IndexEntry) Map {
+	m := Map{CreatedAt: time.Now()}
+
+	entries = index.WithCommonDim(entries)
+	if len(entries) == 0 {
+		return m
+	}
+
+	vecs := make([][]float32, len(entries))
+	for i, e := range entries {
+		// Zero vectors stay at the origin
+		if vecs[i] = index.Normalize(e.Embedding); vecs[i] == nil {
+			vecs[i] = make([]float32, len(e.Embedding))
+		}
+	}
+
+	flat := pca(vecs, 2)
+	for i, p := range flat {
+		if len(p) < 2 {
+			flat[i] = append(p, 0)
+		}
+	}
+	scaleToUnit(flat)
+
+	init := make([][]float64, len(flat))
+	for i, p := range flat {
+		init[i] = []float64{p[0], p[1]}
+	}
+	laid := layout(vecs, init)
+	scaleToUnit(laid)
+
+	m.Points = make([]Point, len(entries))
+	for i, e := range entries {
+		m.Points[i] = Point{
+			Key:  e.Key(),
+			X:    round(laid[i][0]),
+			Y:    round(laid[i][1]),
+			PCAX: round(flat[i][0]),
+			PCAY: round(flat[i][1]),
+		}
+	}
+	return m
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
@@ -0,0 +1,261 @@
+package savedsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// Modes a saved search can run in.
+const (
+	// ModeSearch runs Query through the hybrid searcher.
+	ModeSearch = "search"
+	// ModeSimilar lists bookmarks similar to the SimilarKey bookmark (or,
+	// for searches saved before keys, the SimilarTo ID).
+	ModeSimilar = "similar"
+)
+
+// ErrNotFound is returned for an unknown saved search ID.
+var ErrNotFound = errors.New("saved search not found")
+
+// Search is a named query with its filters.
+type Search struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Mode      string `json:"mode"`
+	Query     string `json:"query,omitempty"`
+	SimilarTo int    `json:"similarTo,omitempty"`
+	// SimilarKey is the index.EntryKey of the SimilarTo bookmark, which
+	// tells apart bookmarks of different users with the same ID.
+	SimilarKey string            `json:"similarKey,omitempty"`
+	UserID     string            `json:"user,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	Network    index.NetworkMode `json:"network,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+	// LastViewedAt is when the results were last fetched; bookmarks indexed
+	// after it are "new".
+	LastViewedAt time.Time `json:"lastViewedAt,omitempty"`
+}
+
+// Filter returns the index filter for the search's user, source and network scope.
+func (s Search) Filter() index.Filter {
+	return index.Filter{UserID: s.UserID, Source: s.Source, Network: s.Network}
+}
+
+// Validate normalizes the search and reports missing or invalid fields.
+func (s *Search) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Query = strings.TrimSpace(s.Query)
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.Mode == "" {
+		s.Mode = ModeSearch
+	}
+	switch s.Mode {
+	case ModeSearch:
+		if s.Query == "" {
+			return errors.New("query is required")
+		}
+	case ModeSimilar:
+		s.SimilarKey = strings.TrimSpace(s.SimilarKey)
+		if s.SimilarTo == 0 && s.SimilarKey == "" {
+			return errors.New("similarTo or similarKey is required in similar mode")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q (want search or similar)", s.Mode)
+	}
+	network, err := index.ParseNetworkMode(string(s.Network))
+	if err != nil {
+		return err
+	}
+	s.Network = network
+	return nil
+}
+
+type file struct {
+	NextID   int      `json:"nextId"`
+	Searches []Search `json:"searches"`
+}
+
+// Store holds saved searches, persisted to saved_searches.json in the data dir.
+type Store struct {
+	mu   sync.Mutex
+	data file
+	path string
+}
+
+func NewStore(dataDir string) *Store {
+	return &Store{
+		data: file{NextID: 1},
+		path: filepath.Join(dataDir, "saved_searches.json"),
+	}
+}
+
+// LoadFromDisk loads saved searches. Returns nil if there are none.
+func (s *Store) LoadFromDisk() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read saved searches file: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("decode saved searches: %w", err)
+	}
+	if f.NextID < 1 {
+		f.NextID = 1
+	}
+
+	s.mu.Lock()
+	s.data = f
+	s.mu.Unlock()
+	return nil
+}
+
+// saveLocked writes the searches to disk. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal saved searches: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write saved searches file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace saved searches file: %w", err)
+	}
+	return nil
+}
+
+// List returns all saved searches in creation order.
+func (s *Store) List() []Search {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Search(nil), s.data.Searches...)
+}
+
+// Get returns the saved search with id.
+func (s *Store) Get(id int) (Search, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(id)
+	if i < 0 {
+		return Search{}, ErrNotFound
+	}
+	return s.data.Searches[i], nil
+}
+
+// Create validates and stores a new saved search, assigning its ID. It
+// counts as viewed now, so "what's new" starts from creation.
+func (s *Store) Create(search Search) (Search, error) {
+	if err := search.Validate(); err != nil {
+		return Search{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	search.ID = s.data.NextID
+	search.CreatedAt, search.UpdatedAt, search.LastViewedAt = now, now, now
+
+	s.data.NextID++
+	s.data.Searches = append(s.data.Searches, search)
+	if err := s.saveLocked(); err != nil {
+		s.data.NextID--
+		s.data.Searches = s.data.Searches[:len(s.data.Searches)-1]
+		return Search{}, err
+	}
+	return search, nil
+}
+
+// Update replaces the name, query and filters of the saved search with id.
+func (s *Store) Update(id int, search Search) (Search, error) {
+	if err := search.Validate(); err != nil {
+		return Search{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return Search{}, ErrNotFound
+	}
+	old := s.data.Searches[i]
+	search.ID = id
+	search.CreatedAt = old.CreatedAt
+	search.LastViewedAt = old.LastViewedAt
+	search.UpdatedAt = time.Now()
+
+	s.data.Searches[i] = search
+	if err := s.saveLocked(); err != nil {
+		s.data.Searches[i] = old
+		return Search{}, err
+	}
+	return search, nil
+}
+
+// Delete removes the saved search with id.
+func (s *Store) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	old := s.data.Searches
+	s.data.Searches = append(append([]Search(nil), old[:i]...), old[i+1:]...)
+	if err := s.saveLocked(); err != nil {
+		s.data.Searches = old
+		return err
+	}
+	return nil
+}
+
+// MarkViewed records that the results of the saved search with id were
+// seen at t, returning the previous view time.
+func (s *Store) MarkViewed(id int, t time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return time.Time{}, ErrNotFound
+	}
+	prev := s.data.Searches[i].LastViewedAt
+	s.data.Searches[i].LastViewedAt = t
+	if err := s.saveLocked(); err != nil {
+		s.data.Searches[i].LastViewedAt = prev
+		return time.Time{}, err
+	}
+	return prev, nil
+}
+
+func (s *Store) indexOf(id int) int {
+	for i, search := range s.data.Searches {
+		if search.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when the next run is due.
+type Schedule interface {
+	// Next returns the first run time after t, or the zero time if there
+	// is none.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Parse reads a schedule: a Go duration ("24h") for a fixed interval, a
+// five-field cron expression ("0 3 * * *") or one of @hourly, @daily,
+// @midnight, @weekly and @monthly. "off" (or "") disables the schedule and
+// returns nil.
+//
+// As in standard cron, when both the day-of-month and weekday fields are
+// restricted a day matching either runs ("0 3 1 * 1" is the 1st and every
+// Monday). A field starting with "*", such as "*/2", is unrestricted for
+// this rule; a list or range such as "1-31" is restricted.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "", "off", "false", "never":
+		return nil, nil
+	}
+
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return nil, fmt.Errorf("interval %s must be positive", spec)
+		}
+		return Every(d), nil
+	}
+
+	expr := spec
+	if macro, ok := cronMacros[spec]; ok {
+		expr = macro
+	}
+	c, err := parseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	c.spec = spec
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron %q never matches", spec)
+	}
+	return c, nil
+}
+
+type interval time.Duration
+
+// Every runs at a fixed interval after the previous scheduling.
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+func (i interval) String() string {
+	return "every " + time.Duration(i).String()
+}
+
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// bits is a set of allowed values of one cron field.
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// cron is a parsed five-field cron expression in the local time zone.
+type cron struct {
+	spec                     string
+	minute, hour, dom, month bits
+	dow                      bits
+	domAny, dowAny           bool
+}
+
+func parseCron(spec string) (*cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: want a duration or 5 cron fields (minute hour day month weekday)", spec)
+	}
+
+	c := &cron{spec: spec}
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron minute: %w", err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron hour: %w", err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron day of month: %w", err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron month: %w", err)
+	}
+	// Weekdays are 0-6 from Sunday; 7 is Sunday too
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron weekday: %w", err)
+	}
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+	c.domAny = strings.HasPrefix(fields[2], "*")
+	c.dowAny = strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+// parseField parses a comma-separated list of "*", "n", "a-b", each
+// optionally followed by "/step".
+func parseField(s string, lo, hi int) (bits, error) {
+	var b bits
+	for _, part := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		from, to := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, z, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			from, err1 = strconv.Atoi(a)
+			to, err2 = strconv.Atoi(z)
+			if err1 != nil || err2 != nil || from > to {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rng)
+			}
+			from, to = n, n
+			if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", rng, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			b |= 1 << uint(v)
+		}
+	}
+	return b, nil
+}
+
+// dayMatches applies cron's rule that when both day of month and weekday
+// are restricted (don't start with "*"), either may match.
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom.has(t.Day()), c.dow.has(int(t.Weekday()))
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// An expression like "0 0 30 2 *" never matches; give up after 5 years
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !c.month.has(int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+		case !c.hour.has(t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cron) String() string {
+	return c.spec
+}
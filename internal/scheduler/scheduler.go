@@ -0,0 +1,151 @@
+// Package scheduler runs background index jobs: periodically on an
+// interval or cron schedule with jitter, or on demand. At most one job
+// runs at a time; a job due while another is running is skipped.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Status describes the scheduler and its runs.
+type Status struct {
+	Enabled  bool
+	Schedule string
+	Running  bool
+	// Reason is why the current run, or else the last one, was started
+	// ("scheduled", "manual", ...).
+	Reason       string
+	LastStart    time.Time
+	LastDuration time.Duration
+	NextRun      time.Time
+	Skipped      int
+}
+
+type Scheduler struct {
+	schedule Schedule
+	jitter   time.Duration
+	job      func()
+
+	mu        sync.Mutex
+	running   bool
+	reason    string
+	lastStart time.Time
+	lastDur   time.Duration
+	next      time.Time
+	skipped   int
+}
+
+// New creates a scheduler that runs job on schedule, delayed by a random
+// amount up to jitter. A nil schedule never runs job on its own, but
+// still serialises runs started with Go.
+func New(schedule Schedule, jitter time.Duration, job func()) *Scheduler {
+	return &Scheduler{
+		schedule: schedule,
+		jitter:   jitter,
+		job:      job,
+	}
+}
+
+// Start runs the scheduled jobs until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	if s.schedule == nil {
+		log.Println("Scheduled re-index disabled")
+		return
+	}
+
+	go func() {
+		for {
+			next := s.schedule.Next(time.Now())
+			if next.IsZero() {
+				log.Printf("Schedule %s has no next run", s.schedule)
+				return
+			}
+			if s.jitter > 0 {
+				next = next.Add(time.Duration(rand.Int63n(int64(s.jitter))))
+			}
+			s.mu.Lock()
+			s.next = next
+			s.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if !s.Go("scheduled", s.job) {
+				s.mu.Lock()
+				s.skipped++
+				s.mu.Unlock()
+				log.Println("Scheduled re-index skipped: a run is already in progress")
+			}
+		}
+	}()
+}
+
+// Go starts fn in the background unless a job is already running, and
+// reports whether it started.
+func (s *Scheduler) Go(reason string, fn func()) bool {
+	if !s.begin(reason) {
+		return false
+	}
+	go func() {
+		defer s.end()
+		fn()
+	}()
+	return true
+}
+
+func (s *Scheduler) begin(reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	s.reason = reason
+	s.lastStart = time.Now()
+	return true
+}
+
+func (s *Scheduler) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.lastDur = time.Since(s.lastStart)
+}
+
+// Running reports whether a job is in progress.
+func (s *Scheduler) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Status returns a snapshot of the scheduler state.
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{
+		Enabled:   s.schedule != nil,
+		Running:   s.running,
+		Reason:    s.reason,
+		LastStart: s.lastStart,
+		NextRun:   s.next,
+		Skipped:   s.skipped,
+	}
+	if s.schedule != nil {
+		st.Schedule = s.schedule.String()
+	}
+	if !s.running {
+		st.LastDuration = s.lastDur
+	}
+	return st
+}
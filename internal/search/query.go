@@ -0,0 +1,54 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// parseQuery strips filter operators such as "has:dead" from a query,
+// applying them to filter, and returns the remaining free text.
+func (s *Searcher) parseQuery(query string, filter *index.Filter) string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			terms = append(terms, field)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "has":
+			switch strings.ToLower(value) {
+			case "dead":
+				filter.Dead = true
+				continue
+			}
+		case "tag":
+			filter.Tag = value
+			continue
+		case "cluster":
+			if id, err := strconv.Atoi(value); err == nil {
+				filter.Keys = intersect(filter.Keys, s.clusters.Keys(id))
+				continue
+			}
+		}
+		terms = append(terms, field)
+	}
+	return strings.Join(terms, " ")
+}
+
+// intersect narrows a key restriction; a nil set means unrestricted.
+func intersect(a, b map[string]bool) map[string]bool {
+	if a == nil {
+		return b
+	}
+	out := make(map[string]bool)
+	for k := range a {
+		if b[k] {
+			out[k] = true
+		}
+	}
+	return out
+}
@@ -2,85 +2,230 @@ package search
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/aryannaik/curius-search/internal/cluster"
+	"github.com/aryannaik/curius-search/internal/dedupe"
 	"github.com/aryannaik/curius-search/internal/embeddings"
 	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/linkcheck"
 )
 
 // Result is a search result returned to the frontend.
 type Result struct {
-	ID          int      `json:"id"`
-	Title       string   `json:"title"`
-	URL         string   `json:"url"`
-	Score       float32  `json:"score"`
-	Snippet     string   `json:"snippet"`
-	Tags        []string `json:"tags"`
-	Highlights  []string `json:"highlights,omitempty"`
-	CreatedAt   string   `json:"createdAt"`
+	// Key identifies the bookmark across users and sources; see index.EntryKey.
+	Key        string   `json:"key"`
+	ID         int      `json:"id"`
+	Source     string   `json:"source"`
+	UserID     string   `json:"userId,omitempty"`
+	Network    bool     `json:"network,omitempty"`
+	Title      string   `json:"title"`
+	URL        string   `json:"url"`
+	Score      float32  `json:"score"`
+	Snippet    string   `json:"snippet"`
+	Tags       []string `json:"tags"`
+	Highlights []string `json:"highlights,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	Dead       bool     `json:"dead,omitempty"`
+	StatusCode int      `json:"statusCode,omitempty"`
+	ArchiveURL string   `json:"archiveUrl,omitempty"`
+	// AlsoSavedAs lists the URLs of duplicates collapsed into this result.
+	AlsoSavedAs []string `json:"alsoSavedAs,omitempty"`
+	Cluster     int      `json:"cluster,omitempty"`
+
+	savedAt time.Time
 }
 
 type Searcher struct {
 	store       *index.Store
 	embedClient *embeddings.Client
+	dupes       *dedupe.Finder
+	clusters    *cluster.Service
 }
 
-func NewSearcher(store *index.Store, embedClient *embeddings.Client) *Searcher {
+func NewSearcher(store *index.Store, embedClient *embeddings.Client, dupes *dedupe.Finder, clusters *cluster.Service) *Searcher {
 	return &Searcher{
 		store:       store,
 		embedClient: embedClient,
+		dupes:       dupes,
+		clusters:    clusters,
 	}
 }
 
-// Search embeds the query and returns the top results using hybrid scoring.
-func (s *Searcher) Search(query string, limit int) ([]Result, error) {
+// Search embeds the query and returns the top results matching filter using hybrid scoring.
+// Operators in the query (e.g. "has:dead") narrow the filter; a query made
+// only of operators lists the matching bookmarks newest first.
+func (s *Searcher) Search(query string, limit int, filter index.Filter) ([]Result, error) {
+	return s.search(query, limit, filter, false)
+}
+
+// SearchRecent is Search ordered newest first: the best matches are found
+// by relevance, then listed by save date, as feeds show them.
+func (s *Searcher) SearchRecent(query string, limit int, filter index.Filter) ([]Result, error) {
+	return s.search(query, limit, filter, true)
+}
+
+func (s *Searcher) search(query string, limit int, filter index.Filter, recent bool) ([]Result, error) {
 	if limit <= 0 {
 		limit = 20
 	}
 
+	// Fetch extra hits so the page stays full after duplicates collapse
+	fetch := limit * 3
+
+	query = s.parseQuery(query, &filter)
+	if query == "" {
+		return s.collapseDuplicates(s.store.List(filter, fetch), limit), nil
+	}
+
 	queryVec, err := s.embedClient.Embed(query)
 	if err != nil {
 		return nil, fmt.Errorf("embed query: %w", err)
 	}
 
-	hits := s.store.Search(queryVec, query, limit)
-	return hitsToResults(hits), nil
+	hits := s.store.Search(queryVec, query, fetch, filter)
+	results := s.collapseDuplicates(hits, limit)
+	if recent {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].savedAt.After(results[j].savedAt)
+		})
+	}
+	return results, nil
+}
+
+// dupKey identifies a set of one user's duplicates, by canonical URL or by
+// duplicate group.
+type dupKey struct {
+	user  string
+	url   string
+	group int
+}
+
+// collapseDuplicates keeps the best-ranked hit of each set of duplicates,
+// listing the other URLs it was saved under in AlsoSavedAs. Hits are
+// duplicates if they belong to the same user and share a canonical URL or
+// a group found by the duplicate finder; other users' saves of a page stay
+// results of their own.
+func (s *Searcher) collapseDuplicates(hits []index.SearchResult, limit int) []Result {
+	dupes := s.dupes.Cached()
+
+	results := make([]Result, 0, limit)
+	seen := make(map[dupKey]int)
+
+	for _, hit := range hits {
+		owner := hit.Entry.UserID
+		byURL := dupKey{user: owner, url: dedupe.CanonicalURL(hit.Entry.URL)}
+		group, inGroup := dupes.GroupOf(hit.Entry.Key())
+		var byGroup dupKey
+		if inGroup {
+			byGroup = dupKey{user: owner, group: group.ID}
+		}
+
+		i, dup := seen[byURL]
+		if !dup && inGroup {
+			i, dup = seen[byGroup]
+		}
+		if dup {
+			results[i].AlsoSavedAs = appendURL(results[i].AlsoSavedAs, results[i].URL, hit.Entry.URL)
+			continue
+		}
+
+		if len(results) == limit {
+			continue
+		}
+
+		r := hitToResult(hit)
+		r.Cluster = s.clusters.ClusterOf(hit.Entry.Key())
+		if inGroup {
+			for _, m := range group.Members {
+				if m.Entry.UserID == owner {
+					r.AlsoSavedAs = appendURL(r.AlsoSavedAs, r.URL, m.Entry.URL)
+				}
+			}
+			seen[byGroup] = len(results)
+		}
+		seen[byURL] = len(results)
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// appendURL adds url to list unless it is the result's own URL or already present.
+func appendURL(list []string, own, url string) []string {
+	if url == own {
+		return list
+	}
+	for _, u := range list {
+		if u == url {
+			return list
+		}
+	}
+	return append(list, url)
 }
 
-// FindSimilar returns bookmarks most similar to the given bookmark ID.
-func (s *Searcher) FindSimilar(id int, limit int) ([]Result, error) {
+// FindSimilar returns bookmarks matching filter most similar to the
+// bookmark with key (see index.EntryKey).
+func (s *Searcher) FindSimilar(key string, limit int, filter index.Filter) ([]Result, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
-	entry := s.store.GetByID(id)
-	if entry == nil {
-		return nil, fmt.Errorf("bookmark %d not found", id)
+	entry, ok := s.store.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("bookmark %s: %w", key, index.ErrNotFound)
 	}
 
-	hits := s.store.SearchByVector(entry.Embedding, limit, id)
+	var hits []index.SearchResult
+	for _, hit := range s.store.SearchByVector(entry.Embedding, 0, key) {
+		if len(hits) == limit {
+			break
+		}
+		if filter.Match(hit.Entry) {
+			hits = append(hits, hit)
+		}
+	}
 	return hitsToResults(hits), nil
 }
 
 func hitsToResults(hits []index.SearchResult) []Result {
 	results := make([]Result, 0, len(hits))
 	for _, hit := range hits {
-		r := Result{
-			ID:         hit.Entry.ID,
-			Title:      hit.Entry.Title,
-			URL:        hit.Entry.URL,
-			Score:      hit.Score,
-			Tags:       hit.Entry.Tags,
-			Highlights: hit.Entry.Highlights,
-			CreatedAt:  hit.Entry.CreatedAt.Format("2006-01-02"),
-		}
-
-		r.Snippet = buildSnippet(hit.Entry)
-		results = append(results, r)
+		results = append(results, hitToResult(hit))
 	}
 	return results
 }
 
+func hitToResult(hit index.SearchResult) Result {
+	r := Result{
+		Key:        hit.Entry.Key(),
+		ID:         hit.Entry.ID,
+		Source:     hit.Entry.SourceName(),
+		UserID:     hit.Entry.UserID,
+		Network:    hit.Entry.Network,
+		Title:      hit.Entry.Title,
+		URL:        hit.Entry.URL,
+		Score:      hit.Score,
+		Tags:       hit.Entry.Tags,
+		Highlights: hit.Entry.Highlights,
+		CreatedAt:  hit.Entry.CreatedAt.Format("2006-01-02"),
+		savedAt:    hit.Entry.CreatedAt,
+	}
+
+	if ls := hit.Entry.LinkStatus; ls != nil {
+		r.StatusCode = ls.StatusCode
+		if ls.Dead {
+			r.Dead = true
+			r.ArchiveURL = linkcheck.WaybackURL(hit.Entry.URL, hit.Entry.CreatedAt)
+		}
+	}
+
+	r.Snippet = buildSnippet(hit.Entry)
+	return r
+}
+
 func buildSnippet(entry index.IndexEntry) string {
 	if entry.Description != "" {
 		s := entry.Description
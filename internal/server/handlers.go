@@ -1,29 +1,80 @@
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aryannaik/curius-search/internal/cluster"
+	"github.com/aryannaik/curius-search/internal/dedupe"
 	"github.com/aryannaik/curius-search/internal/embeddings"
+	"github.com/aryannaik/curius-search/internal/export"
+	"github.com/aryannaik/curius-search/internal/feed"
+	"github.com/aryannaik/curius-search/internal/graph"
 	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/linkcheck"
+	"github.com/aryannaik/curius-search/internal/projection"
+	"github.com/aryannaik/curius-search/internal/savedsearch"
+	"github.com/aryannaik/curius-search/internal/scheduler"
 	"github.com/aryannaik/curius-search/internal/search"
+	"github.com/aryannaik/curius-search/internal/stats"
+	"github.com/aryannaik/curius-search/internal/suggest"
+	"github.com/aryannaik/curius-search/internal/tagsuggest"
 )
 
+//go:embed search.html
+var templates embed.FS
+
 type Handlers struct {
 	searcher    *search.Searcher
 	store       *index.Store
 	embedClient *embeddings.Client
-	reindexFn   func()
+	dupes       *dedupe.Finder
+	clusters    *cluster.Service
+	tags        *tagsuggest.Suggester
+	maps        *projection.Service
+	stats       *stats.Service
+	saved       *savedsearch.Store
+	suggest     *suggest.Suggester
+	sched       *scheduler.Scheduler
+	reindexFn   func(userID string, full bool) bool
+	// users are the configured Curius users, listed in status even before
+	// their first successful sync.
+	users []string
+	// publicURL, if set, is the base of absolute links in feeds and the
+	// OpenSearch description.
+	publicURL string
 }
 
-func NewHandlers(searcher *search.Searcher, store *index.Store, embedClient *embeddings.Client, reindexFn func()) *Handlers {
+func NewHandlers(searcher *search.Searcher, store *index.Store, embedClient *embeddings.Client, dupes *dedupe.Finder, clusters *cluster.Service, tags *tagsuggest.Suggester, maps *projection.Service, stats *stats.Service, saved *savedsearch.Store, suggest *suggest.Suggester, sched *scheduler.Scheduler, reindexFn func(userID string, full bool) bool, users []string, publicURL string) *Handlers {
 	return &Handlers{
 		searcher:    searcher,
 		store:       store,
 		embedClient: embedClient,
+		dupes:       dupes,
+		clusters:    clusters,
+		tags:        tags,
+		maps:        maps,
+		stats:       stats,
+		saved:       saved,
+		suggest:     suggest,
+		sched:       sched,
 		reindexFn:   reindexFn,
+		users:       users,
+		publicURL:   strings.TrimRight(publicURL, "/"),
 	}
 }
 
@@ -42,24 +93,68 @@ func (h *Handlers) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	results, err := h.searcher.Search(query, limit)
+	network, err := index.ParseNetworkMode(r.URL.Query().Get("network"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	filter := index.Filter{
+		UserID:  r.URL.Query().Get("user"),
+		Source:  r.URL.Query().Get("source"),
+		Network: network,
+	}
+
+	results, err := h.searcher.Search(query, limit, filter)
 	if err != nil {
 		log.Printf("Search error: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "search failed"})
 		return
 	}
+	if len(results) > 0 {
+		h.suggest.RecordQuery(query)
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"query":   query,
-		"results": results,
-		"total":   len(results),
+		"query":    query,
+		"user":     filter.UserID,
+		"source":   filter.Source,
+		"network":  filter.Network,
+		"results":  results,
+		"total":    len(results),
+		"indexing": h.sched.Running(),
 	})
 }
 
 type statusResponse struct {
-	IndexCount  int    `json:"indexCount"`
-	UpdatedAt   string `json:"updatedAt"`
-	OllamaOK    bool   `json:"ollamaOk"`
+	IndexCount int            `json:"indexCount"`
+	UpdatedAt  string         `json:"updatedAt"`
+	OllamaOK   bool           `json:"ollamaOk"`
+	Indexing   bool           `json:"indexing"`
+	Users      []userStatus   `json:"users"`
+	Sources    map[string]int `json:"sources"`
+	Sync       syncStatus     `json:"sync"`
+}
+
+// syncStatus reports the re-index scheduler: whether a run is in progress,
+// the last run and the next scheduled one.
+type syncStatus struct {
+	Enabled         bool    `json:"enabled"`
+	Schedule        string  `json:"schedule,omitempty"`
+	Running         bool    `json:"running"`
+	Reason          string  `json:"reason,omitempty"`
+	LastRunAt       string  `json:"lastRunAt,omitempty"`
+	LastDurationSec float64 `json:"lastDurationSec,omitempty"`
+	NextRunAt       string  `json:"nextRunAt,omitempty"`
+	Skipped         int     `json:"skipped,omitempty"`
+}
+
+type userStatus struct {
+	UserID     string `json:"userId"`
+	Count      int    `json:"count"`
+	Network    bool   `json:"network,omitempty"`
+	LastSyncAt string `json:"lastSyncAt,omitempty"`
+	LastError  string `json:"lastError,omitempty"`
 }
 
 func (h *Handlers) HandleStatus(w http.ResponseWriter, r *http.Request) {
@@ -69,57 +164,998 @@ func (h *Handlers) HandleStatus(w http.ResponseWriter, r *http.Request) {
 		updatedStr = updatedAt.Format("2006-01-02T15:04:05Z")
 	}
 
+	// Configured users first, so one whose sync failed still shows its
+	// error, then network users and other owners
+	counts := h.store.UserCounts()
+	userIDs := append([]string(nil), h.users...)
+	listed := make(map[string]bool, len(counts))
+	for _, id := range userIDs {
+		listed[id] = true
+	}
+	var others []string
+	for _, id := range h.store.SyncedUsers() {
+		if !listed[id] {
+			listed[id] = true
+			others = append(others, id)
+		}
+	}
+	for id := range counts {
+		if !listed[id] {
+			listed[id] = true
+			others = append(others, id)
+		}
+	}
+	sort.Strings(others)
+	userIDs = append(userIDs, others...)
+
+	users := make([]userStatus, 0, len(userIDs))
+	for _, id := range userIDs {
+		state := h.store.UserState(id)
+		u := userStatus{
+			UserID:    id,
+			Count:     counts[id],
+			Network:   state.Network,
+			LastError: state.LastError,
+		}
+		if !state.LastSyncAt.IsZero() {
+			u.LastSyncAt = state.LastSyncAt.Format("2006-01-02T15:04:05Z")
+		}
+		users = append(users, u)
+	}
+
 	writeJSON(w, http.StatusOK, statusResponse{
 		IndexCount: h.store.Count(),
 		UpdatedAt:  updatedStr,
 		OllamaOK:   h.embedClient.IsHealthy(),
+		Indexing:   h.sched.Running(),
+		Users:      users,
+		Sources:    h.store.SourceCounts(),
+		Sync:       h.syncStatus(),
 	})
 }
 
+func (h *Handlers) syncStatus() syncStatus {
+	st := h.sched.Status()
+	s := syncStatus{
+		Enabled:         st.Enabled,
+		Schedule:        st.Schedule,
+		Running:         st.Running,
+		Reason:          st.Reason,
+		LastDurationSec: st.LastDuration.Seconds(),
+		Skipped:         st.Skipped,
+	}
+	if !st.LastStart.IsZero() {
+		s.LastRunAt = st.LastStart.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if !st.NextRun.IsZero() {
+		s.NextRunAt = st.NextRun.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return s
+}
+
+// statsResponse is the library analytics returned by /api/stats.
+type statsResponse struct {
+	stats.Stats
+	Model     string `json:"embeddingModel"`
+	DiskBytes int64  `json:"indexSizeBytes"`
+}
+
+// HandleStats returns library analytics: saves over time, top domains and
+// tags, tag co-occurrence, highlight and metadata coverage, and index size.
+func (h *Handlers) HandleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, statsResponse{
+		Stats:     h.stats.Stats(),
+		Model:     h.store.Model(),
+		DiskBytes: h.store.DiskSize(),
+	})
+}
+
+// HandleSimilar lists the bookmarks most similar to the one named by key=,
+// or by id= narrowed with user= and source=; see lookupBookmark.
 func (h *Handlers) HandleSimilar(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	if idStr == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing query parameter 'id'"})
+	entry, ok := h.lookupBookmark(w, r, r.URL.Query().Get("id"))
+	if !ok {
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit := 10
+	if limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	results, err := h.searcher.FindSimilar(entry.Key(), limit, index.Filter{})
+	if err != nil {
+		log.Printf("Similar error: %v", err)
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sourceId":  entry.ID,
+		"sourceKey": entry.Key(),
+		"results":   results,
+		"total":     len(results),
+		"indexing":  h.sched.Running(),
+	})
+}
+
+type brokenLink struct {
+	ID         int    `json:"id"`
+	UserID     string `json:"userId,omitempty"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	FinalURL   string `json:"finalUrl,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CheckedAt  string `json:"checkedAt"`
+	ArchiveURL string `json:"archiveUrl"`
+}
+
+// HandleBrokenLinks reports bookmarks whose last link check found them dead.
+func (h *Handlers) HandleBrokenLinks(w http.ResponseWriter, r *http.Request) {
+	filter := index.Filter{
+		UserID: r.URL.Query().Get("user"),
+		Dead:   true,
+	}
+
+	hits := h.store.List(filter, 0)
+	links := make([]brokenLink, 0, len(hits))
+	for _, hit := range hits {
+		e := hit.Entry
+		links = append(links, brokenLink{
+			ID:         e.ID,
+			UserID:     e.UserID,
+			Title:      e.Title,
+			URL:        e.URL,
+			StatusCode: e.LinkStatus.StatusCode,
+			FinalURL:   e.LinkStatus.FinalURL,
+			Error:      e.LinkStatus.Error,
+			CheckedAt:  e.LinkStatus.CheckedAt.Format("2006-01-02T15:04:05Z"),
+			ArchiveURL: linkcheck.WaybackURL(e.URL, e.CreatedAt),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"links": links,
+		"total": len(links),
+	})
+}
+
+type duplicateGroup struct {
+	ID            int               `json:"id"`
+	Canonical     string            `json:"canonical"`
+	MinSimilarity float32           `json:"minSimilarity"`
+	Members       []duplicateMember `json:"members"`
+}
+
+type duplicateMember struct {
+	ID         int     `json:"id"`
+	UserID     string  `json:"userId,omitempty"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	CreatedAt  string  `json:"createdAt"`
+	Similarity float32 `json:"similarity"`
+}
+
+// HandleDuplicates reports groups of bookmarks that are the same page saved
+// under different URLs. The first member of each group is the oldest save.
+func (h *Handlers) HandleDuplicates(w http.ResponseWriter, r *http.Request) {
+	threshold := float32(dedupe.DefaultThreshold)
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 32)
+		if err != nil || t <= 0 || t > 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "threshold must be in (0, 1]"})
+			return
+		}
+		threshold = float32(t)
+	}
+
+	result := h.dupes.Groups(threshold)
+
+	groups := make([]duplicateGroup, 0, len(result.Groups))
+	for _, g := range result.Groups {
+		dg := duplicateGroup{
+			ID:            g.ID,
+			Canonical:     g.Canonical,
+			MinSimilarity: g.MinSimilarity,
+		}
+		for _, m := range g.Members {
+			dg.Members = append(dg.Members, duplicateMember{
+				ID:         m.Entry.ID,
+				UserID:     m.Entry.UserID,
+				Title:      m.Entry.Title,
+				URL:        m.Entry.URL,
+				CreatedAt:  m.Entry.CreatedAt.Format("2006-01-02"),
+				Similarity: m.Similarity,
+			})
+		}
+		groups = append(groups, dg)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"threshold": threshold,
+		"groups":    groups,
+		"total":     len(groups),
+	})
+}
+
+// HandleClusters lists the topic clusters from the last index run.
+func (h *Handlers) HandleClusters(w http.ResponseWriter, r *http.Request) {
+	clusters := h.clusters.Clusters()
+
+	createdStr := ""
+	if created := h.clusters.CreatedAt(); !created.IsZero() {
+		createdStr = created.Format("2006-01-02T15:04:05Z")
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"clusters":  clusters,
+		"total":     len(clusters),
+		"createdAt": createdStr,
+	})
+}
+
+// HandleSuggestedTags proposes tags for a bookmark from its tagged
+// neighbours. The ID in the path is narrowed with user= and source=, or
+// overridden by key=; see lookupBookmark.
+func (h *Handlers) HandleSuggestedTags(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.lookupBookmark(w, r, r.PathValue("id"))
+	if !ok {
 		return
 	}
 
-	id, err := strconv.Atoi(idStr)
+	limitStr := r.URL.Query().Get("limit")
+	limit := 5
+	if limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	suggestions := h.tags.Suggest(entry, limit)
+	if suggestions == nil {
+		suggestions = []tagsuggest.Suggestion{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":          entry.ID,
+		"key":         entry.Key(),
+		"tags":        entry.Tags,
+		"suggestions": suggestions,
+	})
+}
+
+type mapPoint struct {
+	ID      int      `json:"id"`
+	UserID  string   `json:"userId,omitempty"`
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Tags    []string `json:"tags"`
+	Cluster int      `json:"cluster,omitempty"`
+	X       float64  `json:"x"`
+	Y       float64  `json:"y"`
+}
+
+// HandleMap serves the 2D projection from the last index run. method=pca
+// returns the linear projection instead of the neighbour-preserving layout.
+func (h *Handlers) HandleMap(w http.ResponseWriter, r *http.Request) {
+	pca := r.URL.Query().Get("method") == "pca"
+
+	entries := make(map[string]index.IndexEntry)
+	for _, e := range h.store.Entries() {
+		entries[e.Key()] = e
+	}
+
+	m := h.maps.Map()
+	points := make([]mapPoint, 0, len(m.Points))
+	for _, p := range m.Points {
+		e, ok := entries[p.Key]
+		if !ok {
+			continue
+		}
+		mp := mapPoint{
+			ID:      e.ID,
+			UserID:  e.UserID,
+			Title:   e.Title,
+			URL:     e.URL,
+			Tags:    e.Tags,
+			Cluster: h.clusters.ClusterOf(p.Key),
+			X:       p.X,
+			Y:       p.Y,
+		}
+		if pca {
+			mp.X, mp.Y = p.PCAX, p.PCAY
+		}
+		points = append(points, mp)
+	}
+
+	createdStr := ""
+	if !m.CreatedAt.IsZero() {
+		createdStr = m.CreatedAt.Format("2006-01-02T15:04:05Z")
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"points":    points,
+		"clusters":  h.clusters.Clusters(),
+		"total":     len(points),
+		"createdAt": createdStr,
+	})
+}
+
+// HandleGraph returns a k-nearest-neighbour graph around a seed bookmark
+// (key=, or id= with user= and source=), tag (tag=) or query (q=), as JSON or, with format=graphml|dot, as
+// a file for external graph tools.
+func (h *Handlers) HandleGraph(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	opts := graph.Options{K: 5, Threshold: 0.6, Depth: 2, MaxNodes: 200}
+	if n, err := strconv.Atoi(q.Get("k")); err == nil && n > 0 {
+		opts.K = min(n, 20)
+	}
+	if n, err := strconv.Atoi(q.Get("depth")); err == nil && n >= 0 {
+		opts.Depth = min(n, 4)
+	}
+	if v := q.Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 32)
+		// Written so NaN fails too
+		if err != nil || !(t > 0 && t <= 1) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "threshold must be in (0, 1]"})
+			return
+		}
+		opts.Threshold = float32(t)
+	}
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+		opts.MaxNodes = min(n, 1000)
+	}
+
+	var seeds []index.IndexEntry
+	switch {
+	case q.Get("key") != "" || q.Get("id") != "":
+		entry, ok := h.lookupBookmark(w, r, q.Get("id"))
+		if !ok {
+			return
+		}
+		seeds = append(seeds, entry)
+	case q.Get("tag") != "":
+		for _, hit := range h.store.List(index.Filter{Tag: q.Get("tag")}, 20) {
+			seeds = append(seeds, hit.Entry)
+		}
+	case q.Get("q") != "":
+		vec, err := h.embedClient.Embed(q.Get("q"))
+		if err != nil {
+			log.Printf("Graph error: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to embed query"})
+			return
+		}
+		for _, hit := range h.store.Search(vec, q.Get("q"), 5, index.Filter{}) {
+			seeds = append(seeds, hit.Entry)
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing seed: pass 'key', 'id', 'tag' or 'q'"})
+		return
+	}
+
+	g := graph.Build(h.store, seeds, opts)
+
+	var err error
+	switch q.Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, map[string]any{
+			"nodes": g.Nodes,
+			"edges": g.Edges,
+			"total": len(g.Nodes),
+		})
+		return
+	case "graphml":
+		w.Header().Set("Content-Type", "application/graphml+xml")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.graphml"`)
+		err = graph.WriteGraphML(w, g)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.dot"`)
+		err = graph.WriteDOT(w, g)
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be json, graphml or dot"})
+		return
+	}
+	if err != nil {
+		log.Printf("Graph write error: %v", err)
+	}
+}
+
+// HandleExport streams the index, or the bookmarks matching user, source,
+// tag and network, as a download: format=jsonl (vectors=1 adds
+// embeddings), csv, markdown, or npy for a zip of the float32 matrix and
+// its row ID sidecar.
+func (h *Handlers) HandleExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format, err := export.ParseFormat(q.Get("format"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	network, err := index.ParseNetworkMode(q.Get("network"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	filter := index.Filter{
+		UserID:  q.Get("user"),
+		Source:  q.Get("source"),
+		Tag:     q.Get("tag"),
+		Network: network,
+	}
+
+	switch format {
+	case export.FormatJSONL:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.jsonl"`)
+		vectors, _ := strconv.ParseBool(q.Get("vectors"))
+		_, err = export.WriteJSONL(w, h.store, filter, vectors)
+	case export.FormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.csv"`)
+		_, err = export.WriteCSV(w, h.store, filter)
+	case export.FormatMarkdown:
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.md"`)
+		_, err = export.WriteMarkdown(w, h.store, filter)
+	case export.FormatNPY:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="bookmarks-vectors.zip"`)
+		err = export.NewMatrix(h.store, filter).WriteZip(w)
+	}
+	if err != nil {
+		log.Printf("Export error: %v", err)
+	}
+}
+
+// HandleSavedSearches lists saved searches (GET) or creates one (POST).
+func (h *Handlers) HandleSavedSearches(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		searches := h.saved.List()
+		if searches == nil {
+			searches = []savedsearch.Search{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"searches": searches,
+			"total":    len(searches),
+		})
+	case http.MethodPost:
+		var body savedsearch.Search
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+		if err := body.Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.pinSimilar(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		search, err := h.saved.Create(body)
+		if err != nil {
+			writeSavedSearchError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, search)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// HandleSavedSearch reads (GET), replaces (PUT) or deletes (DELETE) one saved search.
+func (h *Handlers) HandleSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		search, err := h.saved.Get(id)
+		if err != nil {
+			writeSavedSearchError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, search)
+	case http.MethodPut:
+		var body savedsearch.Search
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+		if err := body.Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.pinSimilar(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		search, err := h.saved.Update(id, body)
+		if err != nil {
+			writeSavedSearchError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, search)
+	case http.MethodDelete:
+		if err := h.saved.Delete(id); err != nil {
+			writeSavedSearchError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// HandleSavedSearchResults runs a saved search and marks it viewed. With
+// new=true only bookmarks indexed since the previous view are returned.
+func (h *Handlers) HandleSavedSearchResults(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
 		return
 	}
 
 	limitStr := r.URL.Query().Get("limit")
-	limit := 10
+	limit := 20
 	if limitStr != "" {
 		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
 			limit = n
 		}
 	}
 
-	results, err := h.searcher.FindSimilar(id, limit)
+	saved, err := h.saved.Get(id)
 	if err != nil {
-		log.Printf("Similar error: %v", err)
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		writeSavedSearchError(w, err)
 		return
 	}
 
+	filter := saved.Filter()
+	onlyNew := r.URL.Query().Get("new") == "true"
+	if onlyNew {
+		filter.IndexedAfter = saved.LastViewedAt
+	}
+
+	// Taken before searching so bookmarks indexed meanwhile stay new
+	viewedAt := time.Now()
+	var results []search.Result
+	switch saved.Mode {
+	case savedsearch.ModeSimilar:
+		var key string
+		if key, err = h.similarKey(saved); err == nil {
+			results, err = h.searcher.FindSimilar(key, limit, filter)
+		}
+	default:
+		results, err = h.searcher.Search(saved.Query, limit, filter)
+	}
+	if err != nil {
+		log.Printf("Saved search %d error: %v", id, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "search failed"})
+		return
+	}
+
+	// Only a view of new results that were shown moves the "new" cutoff, so
+	// "show all" loads and empty checks never hide unseen bookmarks
+	if onlyNew && len(results) > 0 {
+		if _, err := h.saved.MarkViewed(id, viewedAt); err != nil {
+			log.Printf("Saved search %d: could not record view: %v", id, err)
+		}
+	}
+
+	sinceStr := ""
+	if onlyNew && !saved.LastViewedAt.IsZero() {
+		sinceStr = saved.LastViewedAt.Format("2006-01-02T15:04:05Z")
+	}
+
 	writeJSON(w, http.StatusOK, map[string]any{
-		"sourceId": id,
+		"search":   saved,
+		"new":      onlyNew,
+		"since":    sinceStr,
 		"results":  results,
 		"total":    len(results),
+		"indexing": h.sched.Running(),
 	})
 }
 
+// pinSimilar records the key of the bookmark a similar-mode search is
+// about, so it keeps following that user's bookmark when another user or
+// source has one with the same ID.
+func (h *Handlers) pinSimilar(s *savedsearch.Search) error {
+	if s.Mode != savedsearch.ModeSimilar {
+		return nil
+	}
+	var entry index.IndexEntry
+	if s.SimilarKey != "" {
+		var ok bool
+		if entry, ok = h.store.Get(s.SimilarKey); !ok {
+			return fmt.Errorf("bookmark %s: %w", s.SimilarKey, index.ErrNotFound)
+		}
+	} else {
+		var err error
+		if entry, err = h.store.Lookup(s.Source, s.UserID, s.SimilarTo); err != nil {
+			return err
+		}
+	}
+	s.SimilarKey, s.SimilarTo = entry.Key(), entry.ID
+	return nil
+}
+
+// similarKey returns the key of the bookmark a similar-mode search is
+// about. Searches saved before keys were pinned are resolved by ID within
+// their user and source.
+func (h *Handlers) similarKey(s savedsearch.Search) (string, error) {
+	if s.SimilarKey != "" {
+		return s.SimilarKey, nil
+	}
+	entry, err := h.store.Lookup(s.Source, s.UserID, s.SimilarTo)
+	if err != nil {
+		return "", err
+	}
+	return entry.Key(), nil
+}
+
+// lookupBookmark finds the bookmark a request names: by key= (see
+// index.EntryKey), else by id narrowed with user= and source=. On failure
+// it writes the error response and reports false.
+func (h *Handlers) lookupBookmark(w http.ResponseWriter, r *http.Request, id string) (index.IndexEntry, bool) {
+	q := r.URL.Query()
+	if key := q.Get("key"); key != "" {
+		entry, ok := h.store.Get(key)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("bookmark %s not found", key)})
+		}
+		return entry, ok
+	}
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing query parameter 'key' or 'id'"})
+		return index.IndexEntry{}, false
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return index.IndexEntry{}, false
+	}
+
+	entry, err := h.store.Lookup(q.Get("source"), q.Get("user"), n)
+	switch {
+	case errors.Is(err, index.ErrAmbiguous):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return entry, false
+	case err != nil:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return entry, false
+	}
+	return entry, true
+}
+
+// writeSavedSearchError maps a saved search store error to a response.
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, savedsearch.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	log.Printf("Saved search error: %v", err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "could not save search"})
+}
+
+// HandleAtomFeed serves bookmarks as an Atom feed; see serveFeed.
+func (h *Handlers) HandleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "atom")
+}
+
+// HandleRSSFeed serves bookmarks as an RSS 2.0 feed; see serveFeed.
+func (h *Handlers) HandleRSSFeed(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "rss")
+}
+
+// serveFeed renders the bookmarks of a saved search (saved=), or the
+// newest matches of a query or tag (q=, tag=), or else the most recently
+// indexed bookmarks. Responses carry an ETag and Last-Modified so feed
+// readers can poll with conditional requests.
+func (h *Handlers) serveFeed(w http.ResponseWriter, r *http.Request, format string) {
+	q := r.URL.Query()
+
+	limit := 30
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+		limit = min(n, 100)
+	}
+
+	network, err := index.ParseNetworkMode(q.Get("network"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// The tag is a filter, not a query operator, so multi-word tags match
+	filter := index.Filter{
+		UserID:  q.Get("user"),
+		Source:  q.Get("source"),
+		Tag:     strings.TrimSpace(q.Get("tag")),
+		Network: network,
+	}
+
+	query := strings.TrimSpace(q.Get("q"))
+
+	var title string
+	var entries []feed.Entry
+	switch {
+	case q.Get("saved") != "":
+		id, err := strconv.Atoi(q.Get("saved"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid saved search id"})
+			return
+		}
+		saved, err := h.saved.Get(id)
+		if err != nil {
+			writeSavedSearchError(w, err)
+			return
+		}
+		var results []search.Result
+		if saved.Mode == savedsearch.ModeSimilar {
+			var key string
+			if key, err = h.similarKey(saved); err == nil {
+				results, err = h.searcher.FindSimilar(key, limit, saved.Filter())
+			}
+		} else {
+			results, err = h.searcher.SearchRecent(saved.Query, limit, saved.Filter())
+		}
+		if err != nil {
+			log.Printf("Feed error: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "search failed"})
+			return
+		}
+		title = "Curius Search: " + saved.Name
+		entries = h.feedEntries(results)
+		if saved.Mode == savedsearch.ModeSimilar {
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].Published.After(entries[j].Published)
+			})
+		}
+	case query != "" || filter.Tag != "":
+		results, err := h.searcher.SearchRecent(query, limit, filter)
+		if err != nil {
+			log.Printf("Feed error: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "search failed"})
+			return
+		}
+		switch {
+		case filter.Tag == "":
+			title = "Curius Search: " + query
+		case query == "":
+			title = fmt.Sprintf("Curius Search: tagged %s", filter.Tag)
+		default:
+			title = fmt.Sprintf("Curius Search: %s (tagged %s)", query, filter.Tag)
+		}
+		entries = h.feedEntries(results)
+	default:
+		for _, hit := range h.store.Recent(filter, limit) {
+			entries = append(entries, feed.EntryFrom(hit.Entry))
+		}
+		title = "Curius Search: recently indexed"
+	}
+
+	base := h.baseURL(r)
+	canonical := *r.URL
+	params := r.URL.Query()
+	params.Del("limit")
+	canonical.RawQuery = params.Encode()
+
+	f := feed.Feed{
+		ID:      base + canonical.RequestURI(),
+		Title:   title,
+		Link:    base + "/",
+		Self:    base + r.URL.RequestURI(),
+		Updated: feed.LastModified(entries),
+		Entries: entries,
+	}
+	if f.Updated.IsZero() {
+		f.Updated = h.store.UpdatedAt()
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == "rss" {
+		contentType = "application/rss+xml; charset=utf-8"
+		err = feed.WriteRSS(&buf, f)
+	} else {
+		err = feed.WriteAtom(&buf, f)
+	}
+	if err != nil {
+		log.Printf("Feed error: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "could not render feed"})
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if !f.Updated.IsZero() {
+		w.Header().Set("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, f.Updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// feedEntries looks up the index entries behind search results, so feeds
+// get full timestamps and descriptions.
+func (h *Handlers) feedEntries(results []search.Result) []feed.Entry {
+	entries := make([]feed.Entry, 0, len(results))
+	for _, res := range results {
+		if e, ok := h.store.Get(res.Key); ok {
+			entries = append(entries, feed.EntryFrom(e))
+		}
+	}
+	return entries
+}
+
+// notModified reports whether a conditional request's If-None-Match or,
+// failing that, If-Modified-Since shows the client already has the response.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// baseURL returns the configured public URL, else the scheme and host the
+// request was made to. Forwarded headers are not trusted; behind a reverse
+// proxy set server.public_url.
+func (h *Handlers) baseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// HandleOpenSearch serves the OpenSearch description document that lets
+// browsers add the instance as a search engine.
+func (h *Handlers) HandleOpenSearch(w http.ResponseWriter, r *http.Request) {
+	base := h.baseURL(r)
+
+	w.Header().Set("Content-Type", "application/opensearchdescription+xml; charset=utf-8")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/" xmlns:moz="http://www.mozilla.org/2006/browser/search/">
+  <ShortName>Curius Search</ShortName>
+  <Description>Semantic search across your Curius bookmarks</Description>
+  <InputEncoding>UTF-8</InputEncoding>
+  <Url type="text/html" method="get" template="%[1]s/search?q={searchTerms}"/>
+  <Url type="application/x-suggestions+json" method="get" template="%[1]s/api/opensearch/suggest?q={searchTerms}"/>
+  <Url type="application/opensearchdescription+xml" rel="self" template="%[1]s/opensearch.xml"/>
+  <moz:SearchForm>%[1]s/</moz:SearchForm>
+</OpenSearchDescription>
+`, html.EscapeString(base))
+}
+
+// HandleOpenSearchSuggest returns completions in the OpenSearch suggestions
+// format: a JSON array of the query and a list of completions.
+func (h *Handlers) HandleOpenSearchSuggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	completions := []string{}
+	for _, c := range h.suggest.Complete(q, suggest.MaxLimit) {
+		completions = append(completions, c.Text)
+	}
+	w.Header().Set("Content-Type", "application/x-suggestions+json")
+	json.NewEncoder(w).Encode([]any{q, completions})
+}
+
+// HandleSuggest returns typeahead completions for a prefix from titles,
+// tags, domains and past queries. It never calls the embedder.
+func (h *Handlers) HandleSuggest(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	limitStr := r.URL.Query().Get("limit")
+	limit := 8
+	if limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			limit = min(n, suggest.MaxLimit)
+		}
+	}
+
+	start := time.Now()
+	completions := h.suggest.Complete(prefix, limit)
+	took := time.Since(start)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"prefix":      prefix,
+		"completions": completions,
+		"tookUs":      took.Microseconds(),
+	})
+}
+
+// searchPage is the data for the server-rendered search page.
+type searchPage struct {
+	Query string
+	// Tag filters the results; tag pills link with it rather than a tag:
+	// operator so multi-word tags stay whole.
+	Tag      string
+	Results  []search.Result
+	Error    string
+	Indexing bool
+}
+
+var searchTemplate = template.Must(template.New("search.html").Funcs(template.FuncMap{
+	"percent": func(score float32) string { return strconv.FormatFloat(float64(score)*100, 'f', 1, 32) },
+	"domain": func(rawURL string) string {
+		if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return rawURL
+	},
+}).ParseFS(templates, "search.html"))
+
+// HandleSearchPage renders search results as HTML, for browser address-bar
+// searches and clients without JavaScript.
+func (h *Handlers) HandleSearchPage(w http.ResponseWriter, r *http.Request) {
+	page := searchPage{
+		Query:    strings.TrimSpace(r.URL.Query().Get("q")),
+		Tag:      strings.TrimSpace(r.URL.Query().Get("tag")),
+		Indexing: h.sched.Running(),
+	}
+
+	if page.Query != "" || page.Tag != "" {
+		results, err := h.searcher.Search(page.Query, 20, index.Filter{Tag: page.Tag})
+		if err != nil {
+			log.Printf("Search error: %v", err)
+			page.Error = "search failed"
+		} else if len(results) > 0 && page.Query != "" {
+			h.suggest.RecordQuery(page.Query)
+		}
+		page.Results = results
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := searchTemplate.Execute(w, page); err != nil {
+		log.Printf("Render search page: %v", err)
+	}
+}
+
 func (h *Handlers) HandleReindex(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
 		return
 	}
 
-	go h.reindexFn()
+	// user limits the run to one Curius user; full discards their existing
+	// embeddings first (or everyone's when no user is given).
+	userID := r.URL.Query().Get("user")
+	full := r.URL.Query().Get("full") == "true"
+	if !h.reindexFn(userID, full) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "an index run is already in progress"})
+		return
+	}
 
-	writeJSON(w, http.StatusOK, map[string]string{"status": "reindex started"})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "reindex started", "user": userID, "full": full})
 }
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
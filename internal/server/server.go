@@ -4,20 +4,46 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/aryannaik/curius-search/internal/cluster"
+	"github.com/aryannaik/curius-search/internal/dedupe"
 	"github.com/aryannaik/curius-search/internal/embeddings"
 	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/projection"
+	"github.com/aryannaik/curius-search/internal/savedsearch"
+	"github.com/aryannaik/curius-search/internal/scheduler"
 	"github.com/aryannaik/curius-search/internal/search"
+	"github.com/aryannaik/curius-search/internal/stats"
+	"github.com/aryannaik/curius-search/internal/suggest"
+	"github.com/aryannaik/curius-search/internal/tagsuggest"
 )
 
-func New(port string, staticDir string, store *index.Store, embedClient *embeddings.Client, reindexFn func()) *http.Server {
-	searcher := search.NewSearcher(store, embedClient)
-	handlers := NewHandlers(searcher, store, embedClient, reindexFn)
+func New(port string, staticDir string, store *index.Store, embedClient *embeddings.Client, clusters *cluster.Service, maps *projection.Service, saved *savedsearch.Store, sched *scheduler.Scheduler, reindexFn func(userID string, full bool) bool, users []string, publicURL string) *http.Server {
+	dupes := dedupe.NewFinder(store)
+	searcher := search.NewSearcher(store, embedClient, dupes, clusters)
+	handlers := NewHandlers(searcher, store, embedClient, dupes, clusters, tagsuggest.NewSuggester(store), maps, stats.NewService(store), saved, suggest.NewSuggester(store), sched, reindexFn, users, publicURL)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/search", handlers.HandleSearch)
 	mux.HandleFunc("/api/similar", handlers.HandleSimilar)
 	mux.HandleFunc("/api/status", handlers.HandleStatus)
+	mux.HandleFunc("/api/stats", handlers.HandleStats)
 	mux.HandleFunc("/api/reindex", handlers.HandleReindex)
+	mux.HandleFunc("/api/links/broken", handlers.HandleBrokenLinks)
+	mux.HandleFunc("/api/duplicates", handlers.HandleDuplicates)
+	mux.HandleFunc("/api/clusters", handlers.HandleClusters)
+	mux.HandleFunc("/api/bookmarks/{id}/suggested-tags", handlers.HandleSuggestedTags)
+	mux.HandleFunc("/api/map", handlers.HandleMap)
+	mux.HandleFunc("/api/graph", handlers.HandleGraph)
+	mux.HandleFunc("/api/export", handlers.HandleExport)
+	mux.HandleFunc("/api/saved-searches", handlers.HandleSavedSearches)
+	mux.HandleFunc("/api/saved-searches/{id}", handlers.HandleSavedSearch)
+	mux.HandleFunc("/api/saved-searches/{id}/results", handlers.HandleSavedSearchResults)
+	mux.HandleFunc("/opensearch.xml", handlers.HandleOpenSearch)
+	mux.HandleFunc("/api/suggest", handlers.HandleSuggest)
+	mux.HandleFunc("/api/opensearch/suggest", handlers.HandleOpenSearchSuggest)
+	mux.HandleFunc("/search", handlers.HandleSearchPage)
+	mux.HandleFunc("/feed.atom", handlers.HandleAtomFeed)
+	mux.HandleFunc("/feed.rss", handlers.HandleRSSFeed)
 	mux.Handle("/", http.FileServer(http.Dir(staticDir)))
 
 	srv := &http.Server{
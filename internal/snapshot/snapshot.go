@@ -0,0 +1,194 @@
+// Package snapshot keeps named copies of the index in the data dir, so a
+// bad re-index (say, with a worse embedding model) can be rolled back.
+// Each snapshot is a directory under <data dir>/snapshots holding the index
+// and a small metadata file, so listing them never reads the index.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// ErrNotFound is returned for an unknown snapshot name.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Meta describes a snapshot.
+type Meta struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model,omitempty"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Reason is why the snapshot was taken ("manual", "pre-reindex", ...).
+	Reason string `json:"reason,omitempty"`
+	Size   int64  `json:"size"`
+}
+
+// Retention decides which snapshots Prune removes. Zero fields don't limit.
+type Retention struct {
+	// Keep is how many of the newest snapshots to keep.
+	Keep int
+	// MaxAge removes snapshots older than this.
+	MaxAge time.Duration
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Manager creates, lists, restores and prunes snapshots.
+type Manager struct {
+	dir string
+}
+
+// NewManager returns a manager for the snapshots in dataDir.
+func NewManager(dataDir string) *Manager {
+	return &Manager{dir: filepath.Join(dataDir, "snapshots")}
+}
+
+// Create snapshots the store as name, or a timestamped name if it is empty.
+func (m *Manager) Create(store *index.Store, name, reason string) (Meta, error) {
+	now := time.Now()
+	if name == "" {
+		name = now.Format("20060102-150405")
+		if reason != "" && reason != "manual" {
+			name = reason + "-" + name
+		}
+	}
+	if !validName.MatchString(name) {
+		return Meta{}, fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+
+	dir := filepath.Join(m.dir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return Meta{}, fmt.Errorf("snapshot %s already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Meta{}, fmt.Errorf("create snapshot dir: %w", err)
+	}
+
+	path := filepath.Join(dir, "index.json")
+	count, model, err := store.SaveCopy(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return Meta{}, err
+	}
+	meta := Meta{
+		Name:      name,
+		Model:     model,
+		Count:     count,
+		CreatedAt: now,
+		Reason:    reason,
+	}
+	if info, err := os.Stat(path); err == nil {
+		meta.Size = info.Size()
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return Meta{}, fmt.Errorf("marshal snapshot metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), data, 0644); err != nil {
+		os.RemoveAll(dir)
+		return Meta{}, fmt.Errorf("write snapshot metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// List returns the snapshots, newest first.
+func (m *Manager) List() ([]Meta, error) {
+	dirs, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshots: %w", err)
+	}
+
+	var metas []Meta
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		meta, err := m.Get(d.Name())
+		if err != nil {
+			// Skip snapshots interrupted while being written
+			continue
+		}
+		metas = append(metas, meta)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].CreatedAt.After(metas[j].CreatedAt)
+	})
+	return metas, nil
+}
+
+// Get returns the metadata of the snapshot called name.
+func (m *Manager) Get(name string) (Meta, error) {
+	if !validName.MatchString(name) {
+		return Meta{}, ErrNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(m.dir, name, "meta.json"))
+	if os.IsNotExist(err) {
+		return Meta{}, ErrNotFound
+	}
+	if err != nil {
+		return Meta{}, fmt.Errorf("read snapshot metadata: %w", err)
+	}
+	var meta Meta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Meta{}, fmt.Errorf("decode snapshot metadata: %w", err)
+	}
+	meta.Name = name
+	return meta, nil
+}
+
+// Restore replaces the store's index with the snapshot called name.
+func (m *Manager) Restore(store *index.Store, name string) (Meta, error) {
+	meta, err := m.Get(name)
+	if err != nil {
+		return Meta{}, err
+	}
+	if err := store.Restore(filepath.Join(m.dir, name, "index.json")); err != nil {
+		return Meta{}, err
+	}
+	return meta, nil
+}
+
+// Delete removes the snapshot called name.
+func (m *Manager) Delete(name string) error {
+	if _, err := m.Get(name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(m.dir, name)); err != nil {
+		return fmt.Errorf("remove snapshot: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the snapshots r does not keep and returns them.
+func (m *Manager) Prune(r Retention) ([]Meta, error) {
+	metas, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []Meta
+	for i, meta := range metas {
+		tooMany := r.Keep > 0 && i >= r.Keep
+		tooOld := r.MaxAge > 0 && time.Since(meta.CreatedAt) > r.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := m.Delete(meta.Name); err != nil {
+			return removed, err
+		}
+		removed = append(removed, meta)
+	}
+	return removed, nil
+}
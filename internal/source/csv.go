@@ -0,0 +1,120 @@
+package source
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CSV imports Pocket and Raindrop.io CSV exports. Columns are matched by
+// header name, so any CSV with at least a "url" column is accepted.
+//
+// Pocket:   title,url,time_added,tags,status        (tags separated by "|")
+// Raindrop: id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite
+type CSV struct {
+	path string
+	name string
+}
+
+// Name reports the service detected from the header, or "csv" before Links is called.
+func (c *CSV) Name() string {
+	if c.name == "" {
+		return NameCSV
+	}
+	return c.name
+}
+
+func (c *CSV) Links() ([]Link, error) {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("open csv: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := cols["url"]; !ok {
+		return nil, fmt.Errorf("csv has no url column")
+	}
+
+	c.name = NameCSV
+	tagSep := ","
+	switch {
+	case has(cols, "time_added"):
+		c.name = NamePocket
+		tagSep = "|"
+	case has(cols, "excerpt") || has(cols, "folder"):
+		c.name = NameRaindrop
+	}
+
+	get := func(rec []string, col string) string {
+		i, ok := cols[col]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var links []Link
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+
+		l := Link{
+			Source:      c.name,
+			Title:       get(rec, "title"),
+			URL:         get(rec, "url"),
+			Description: firstNonEmpty(get(rec, "excerpt"), get(rec, "note"), get(rec, "description")),
+			Tags:        splitTags(get(rec, "tags"), tagSep),
+			CreatedAt:   parseTime(firstNonEmpty(get(rec, "created"), get(rec, "time_added"), get(rec, "createdat"))),
+		}
+		if l.URL == "" {
+			continue
+		}
+		for _, h := range strings.Split(get(rec, "highlights"), "\n") {
+			if h = strings.TrimSpace(strings.TrimPrefix(h, "Highlight:")); h != "" {
+				l.Highlights = append(l.Highlights, h)
+			}
+		}
+
+		l.ID = StableID(l.URL)
+		if id, err := strconv.Atoi(get(rec, "id")); err == nil && id > 0 {
+			l.ID = id
+		}
+		links = append(links, l)
+	}
+
+	return links, nil
+}
+
+func has(cols map[string]int, col string) bool {
+	_, ok := cols[col]
+	return ok
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
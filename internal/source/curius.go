@@ -0,0 +1,62 @@
+package source
+
+import "github.com/aryannaik/curius-search/internal/curius"
+
+// Curius fetches a user's bookmarks from the Curius API.
+type Curius struct {
+	client *curius.Client
+}
+
+func NewCurius(userID string) *Curius {
+	return &Curius{client: curius.NewClient(userID)}
+}
+
+func (c *Curius) Name() string { return NameCurius }
+
+// Links fetches every page of the user's bookmarks.
+func (c *Curius) Links() ([]Link, error) {
+	links, err := c.client.FetchAllLinks()
+	if err != nil {
+		return nil, err
+	}
+	return FromCurius(links), nil
+}
+
+// CuriusDump reads Curius bookmarks from captured API responses, so an index
+// can be built without reaching curius.app. Links keep their Curius IDs and
+// source, so a later live sync only embeds what the dump was missing.
+type CuriusDump struct {
+	path string
+}
+
+func (d *CuriusDump) Name() string { return NameCurius }
+
+func (d *CuriusDump) Links() ([]Link, error) {
+	links, err := curius.LoadDump(d.path)
+	if err != nil {
+		return nil, err
+	}
+	return FromCurius(links), nil
+}
+
+// FromCurius normalizes links returned by the Curius client.
+func FromCurius(links []curius.Link) []Link {
+	out := make([]Link, 0, len(links))
+	for _, l := range links {
+		tags := make([]string, len(l.Tags))
+		for i, t := range l.Tags {
+			tags[i] = t.Name
+		}
+		out = append(out, Link{
+			ID:          l.ID,
+			Source:      NameCurius,
+			Title:       l.Title,
+			URL:         l.URL,
+			Description: l.Description,
+			Highlights:  l.Highlights,
+			Tags:        tags,
+			CreatedAt:   l.CreatedAt,
+		})
+	}
+	return out
+}
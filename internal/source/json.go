@@ -0,0 +1,152 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// JSON imports Pocket and Raindrop.io JSON exports.
+//
+// Pocket's retrieve API returns {"list": {"<item_id>": {...}}}; Raindrop's
+// returns {"items": [...]} (a bare array of items is accepted too).
+type JSON struct {
+	path string
+	name string
+}
+
+// Name reports the service detected from the file, or "raindrop" before Links is called.
+func (j *JSON) Name() string {
+	if j.name == "" {
+		return NameRaindrop
+	}
+	return j.name
+}
+
+type pocketExport struct {
+	List map[string]pocketItem `json:"list"`
+}
+
+type pocketItem struct {
+	ItemID        string `json:"item_id"`
+	GivenURL      string `json:"given_url"`
+	ResolvedURL   string `json:"resolved_url"`
+	GivenTitle    string `json:"given_title"`
+	ResolvedTitle string `json:"resolved_title"`
+	Excerpt       string `json:"excerpt"`
+	TimeAdded     string `json:"time_added"`
+	Tags          map[string]struct {
+		Tag string `json:"tag"`
+	} `json:"tags"`
+}
+
+type raindropExport struct {
+	Items []raindropItem `json:"items"`
+}
+
+type raindropItem struct {
+	ID         int      `json:"_id"`
+	Link       string   `json:"link"`
+	Title      string   `json:"title"`
+	Excerpt    string   `json:"excerpt"`
+	Note       string   `json:"note"`
+	Tags       []string `json:"tags"`
+	Created    string   `json:"created"`
+	Highlights []struct {
+		Text string `json:"text"`
+	} `json:"highlights"`
+}
+
+func (j *JSON) Links() ([]Link, error) {
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return nil, fmt.Errorf("read json export: %w", err)
+	}
+
+	var items []raindropItem
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("decode raindrop export: %w", err)
+		}
+	} else {
+		var probe struct {
+			List  json.RawMessage `json:"list"`
+			Items json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("decode json export: %w", err)
+		}
+
+		switch {
+		case probe.List != nil:
+			var export pocketExport
+			if err := json.Unmarshal(data, &export); err != nil {
+				return nil, fmt.Errorf("decode pocket export: %w", err)
+			}
+			j.name = NamePocket
+			return pocketLinks(export), nil
+		case probe.Items != nil:
+			var export raindropExport
+			if err := json.Unmarshal(data, &export); err != nil {
+				return nil, fmt.Errorf("decode raindrop export: %w", err)
+			}
+			items = export.Items
+		default:
+			return nil, fmt.Errorf("unrecognized json export: expected a Pocket \"list\" or Raindrop \"items\"")
+		}
+	}
+
+	j.name = NameRaindrop
+	links := make([]Link, 0, len(items))
+	for _, it := range items {
+		if it.Link == "" {
+			continue
+		}
+		l := Link{
+			ID:          it.ID,
+			Source:      NameRaindrop,
+			Title:       it.Title,
+			URL:         it.Link,
+			Description: firstNonEmpty(it.Excerpt, it.Note),
+			Tags:        it.Tags,
+			CreatedAt:   parseTime(it.Created),
+		}
+		if l.ID <= 0 {
+			l.ID = StableID(l.URL)
+		}
+		for _, h := range it.Highlights {
+			if h.Text != "" {
+				l.Highlights = append(l.Highlights, h.Text)
+			}
+		}
+		links = append(links, l)
+	}
+	return links, nil
+}
+
+func pocketLinks(export pocketExport) []Link {
+	links := make([]Link, 0, len(export.List))
+	for _, it := range export.List {
+		l := Link{
+			Source:      NamePocket,
+			Title:       firstNonEmpty(it.ResolvedTitle, it.GivenTitle),
+			URL:         firstNonEmpty(it.ResolvedURL, it.GivenURL),
+			Description: it.Excerpt,
+			CreatedAt:   parseTime(it.TimeAdded),
+		}
+		if l.URL == "" {
+			continue
+		}
+		for tag := range it.Tags {
+			l.Tags = append(l.Tags, tag)
+		}
+		l.ID = StableID(l.URL)
+		if id, err := strconv.Atoi(it.ItemID); err == nil && id > 0 {
+			l.ID = id
+		}
+		links = append(links, l)
+	}
+	return links
+}
@@ -0,0 +1,77 @@
+package source
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// JSONL imports one bookmark per line in a generic format:
+//
+//	{"url": "...", "title": "...", "description": "...", "tags": ["..."],
+//	 "highlights": ["..."], "createdAt": "2024-01-02T15:04:05Z", "id": 42}
+//
+// Only url is required; id defaults to a hash of the URL.
+type JSONL struct {
+	path string
+}
+
+func (j *JSONL) Name() string { return NameJSONL }
+
+type jsonlRecord struct {
+	ID          int      `json:"id"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Highlights  []string `json:"highlights"`
+	CreatedAt   string   `json:"createdAt"`
+}
+
+func (j *JSONL) Links() ([]Link, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, fmt.Errorf("open jsonl: %w", err)
+	}
+	defer f.Close()
+
+	var links []Link
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("decode line %d: %w", line, err)
+		}
+		if rec.URL == "" {
+			return nil, fmt.Errorf("line %d: missing url", line)
+		}
+
+		l := Link{
+			ID:          rec.ID,
+			Source:      NameJSONL,
+			Title:       rec.Title,
+			URL:         rec.URL,
+			Description: rec.Description,
+			Tags:        rec.Tags,
+			Highlights:  rec.Highlights,
+			CreatedAt:   parseTime(rec.CreatedAt),
+		}
+		if l.ID <= 0 {
+			l.ID = StableID(l.URL)
+		}
+		links = append(links, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read jsonl: %w", err)
+	}
+
+	return links, nil
+}
@@ -0,0 +1,100 @@
+package source
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Netscape imports the bookmark HTML files exported by Chrome, Firefox and
+// most other browsers. Firefox TAGS attributes become tags; the folders a
+// bookmark sits in are added as tags too, since Chrome has no other grouping.
+type Netscape struct {
+	path string
+}
+
+func (n *Netscape) Name() string { return NameNetscape }
+
+var (
+	netscapeToken = regexp.MustCompile(`(?is)<h3[^>]*>(.*?)</h3>|<a\s([^>]*)>(.*?)</a>|<dd>([^<]*)|<dl>|</dl>`)
+	netscapeAttr  = regexp.MustCompile(`(?i)([a-z_]+)="([^"]*)"`)
+)
+
+func (n *Netscape) Links() ([]Link, error) {
+	data, err := os.ReadFile(n.path)
+	if err != nil {
+		return nil, fmt.Errorf("read bookmarks file: %w", err)
+	}
+
+	var (
+		links   []Link
+		folders []string
+		pending string
+		last    = -1 // index of the link a <DD> description belongs to
+	)
+
+	for _, m := range netscapeToken.FindAllStringSubmatch(string(data), -1) {
+		token := strings.ToLower(m[0])
+		if !strings.HasPrefix(token, "<dd>") {
+			last = -1
+		}
+
+		switch {
+		case strings.HasPrefix(token, "<h3"):
+			pending = html.UnescapeString(strings.TrimSpace(m[1]))
+		case token == "<dl>":
+			folders = append(folders, pending)
+			pending = ""
+		case token == "</dl>":
+			if len(folders) > 0 {
+				folders = folders[:len(folders)-1]
+			}
+		case strings.HasPrefix(token, "<dd>"):
+			if last >= 0 {
+				links[last].Description = html.UnescapeString(strings.TrimSpace(m[4]))
+			}
+		case strings.HasPrefix(token, "<a"):
+			if l, ok := parseNetscapeAnchor(m[2], m[3], folders); ok {
+				links = append(links, l)
+				last = len(links) - 1
+			}
+		}
+	}
+
+	return links, nil
+}
+
+func parseNetscapeAnchor(attrs, title string, folders []string) (Link, bool) {
+	l := Link{
+		Source: NameNetscape,
+		Title:  html.UnescapeString(strings.TrimSpace(title)),
+	}
+
+	for _, a := range netscapeAttr.FindAllStringSubmatch(attrs, -1) {
+		value := html.UnescapeString(a[2])
+		switch strings.ToUpper(a[1]) {
+		case "HREF":
+			l.URL = value
+		case "ADD_DATE":
+			l.CreatedAt = parseTime(value)
+		case "TAGS":
+			l.Tags = append(l.Tags, splitTags(value, ",")...)
+		}
+	}
+
+	// Skip bookmarklets and browser-internal pages
+	if !strings.HasPrefix(l.URL, "http://") && !strings.HasPrefix(l.URL, "https://") {
+		return Link{}, false
+	}
+
+	for _, f := range folders {
+		if f != "" {
+			l.Tags = append(l.Tags, f)
+		}
+	}
+
+	l.ID = StableID(l.URL)
+	return l, true
+}
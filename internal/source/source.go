@@ -0,0 +1,123 @@
+package source
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/curius"
+)
+
+// Source names recorded on index entries.
+const (
+	NameCurius   = "curius"
+	NameNetscape = "netscape"
+	NamePocket   = "pocket"
+	NameRaindrop = "raindrop"
+	NameCSV      = "csv"
+	NameJSONL    = "jsonl"
+)
+
+// Link is a bookmark normalized across services.
+type Link struct {
+	ID          int       `json:"id"`
+	Source      string    `json:"source"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Highlights  []string  `json:"highlights,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Source yields normalized links from a bookmarking service or export file.
+type Source interface {
+	// Name identifies the source in index entries and search filters.
+	Name() string
+	// Links returns every bookmark the source knows about.
+	Links() ([]Link, error)
+}
+
+// Open returns an importer for an export file or Curius dump directory. An
+// empty or "auto" format is detected from the path.
+func Open(path, format string) (Source, error) {
+	if format == "" || format == "auto" {
+		format = detectFormat(path)
+	}
+
+	switch format {
+	case "curius":
+		return &CuriusDump{path: path}, nil
+	case "netscape", "html":
+		return &Netscape{path: path}, nil
+	case "csv":
+		return &CSV{path: path}, nil
+	case "json":
+		return &JSON{path: path}, nil
+	case "jsonl":
+		return &JSONL{path: path}, nil
+	}
+	return nil, fmt.Errorf("unknown import format %q (want curius, netscape, csv, json or jsonl)", format)
+}
+
+func detectFormat(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "curius"
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "netscape"
+	case ".csv":
+		return "csv"
+	case ".json":
+		if data, err := os.ReadFile(path); err == nil && curius.IsDump(data) {
+			return "curius"
+		}
+		return "json"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	}
+	return ""
+}
+
+// StableID derives a positive bookmark ID from a URL for sources that don't
+// assign numeric IDs. It stays within the range JavaScript numbers represent exactly.
+func StableID(url string) int {
+	h := fnv.New64a()
+	h.Write([]byte(url))
+	return int(h.Sum64() & (1<<53 - 1))
+}
+
+// splitTags splits a delimited tag list, trimming blanks.
+func splitTags(s string, sep string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, sep) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// parseTime accepts the timestamp formats found in bookmark exports:
+// Unix seconds, RFC 3339 and ISO 8601 with milliseconds.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC()
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.000Z", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,215 @@
+package stats
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+// topN bounds the ranked lists (domains, tags, tag pairs).
+const topN = 20
+
+// Stats summarizes the library.
+type Stats struct {
+	Total    int          `json:"total"`
+	Timeline []MonthCount `json:"timeline"`
+	Domains  []Count      `json:"topDomains"`
+	Tags     []Count      `json:"topTags"`
+	TagPairs []TagPair    `json:"tagCooccurrence"`
+
+	Highlights HighlightStats `json:"highlights"`
+	Missing    MissingStats   `json:"missing"`
+	// Dim is the embedding dimension, 0 for an empty index.
+	Dim int `json:"embeddingDim"`
+}
+
+// MonthCount is the number of bookmarks saved in a month ("2006-01").
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int    `json:"count"`
+}
+
+// Count is a ranked name with its number of bookmarks.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TagPair is two tags and how many bookmarks carry both.
+type TagPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int    `json:"count"`
+}
+
+// HighlightStats counts highlights across the library.
+type HighlightStats struct {
+	Total       int     `json:"total"`
+	Entries     int     `json:"entries"`
+	PerBookmark float64 `json:"perBookmark"`
+}
+
+// MissingStats is the percentage of bookmarks lacking each field.
+type MissingStats struct {
+	Tags        float64 `json:"tags"`
+	Highlights  float64 `json:"highlights"`
+	Description float64 `json:"description"`
+}
+
+// Compute summarizes entries.
+func Compute(entries []index.IndexEntry) Stats {
+	st := Stats{Total: len(entries)}
+
+	months := make(map[string]int)
+	domains := make(map[string]int)
+	tags := make(map[string]int)
+	pairs := make(map[[2]string]int)
+	var noTags, noHighlights, noDescription int
+
+	for _, e := range entries {
+		if st.Dim == 0 {
+			st.Dim = len(e.Embedding)
+		}
+		if !e.CreatedAt.IsZero() {
+			months[e.CreatedAt.Format("2006-01")]++
+		}
+		if d := Domain(e.URL); d != "" {
+			domains[d]++
+		}
+
+		entryTags := uniqueTags(e.Tags)
+		for i, a := range entryTags {
+			tags[a]++
+			for _, b := range entryTags[i+1:] {
+				pairs[[2]string{a, b}]++
+			}
+		}
+
+		st.Highlights.Total += len(e.Highlights)
+		if len(e.Highlights) > 0 {
+			st.Highlights.Entries++
+		} else {
+			noHighlights++
+		}
+		if len(e.Tags) == 0 {
+			noTags++
+		}
+		if e.Description == "" {
+			noDescription++
+		}
+	}
+
+	st.Timeline = make([]MonthCount, 0, len(months))
+	for m, n := range months {
+		st.Timeline = append(st.Timeline, MonthCount{Month: m, Count: n})
+	}
+	sort.Slice(st.Timeline, func(i, j int) bool { return st.Timeline[i].Month < st.Timeline[j].Month })
+
+	st.Domains = top(domains)
+	st.Tags = top(tags)
+
+	st.TagPairs = make([]TagPair, 0, len(pairs))
+	for p, n := range pairs {
+		st.TagPairs = append(st.TagPairs, TagPair{A: p[0], B: p[1], Count: n})
+	}
+	sort.Slice(st.TagPairs, func(i, j int) bool {
+		a, b := st.TagPairs[i], st.TagPairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.A+"\x00"+a.B < b.A+"\x00"+b.B
+	})
+	if len(st.TagPairs) > topN {
+		st.TagPairs = st.TagPairs[:topN]
+	}
+
+	if st.Total > 0 {
+		st.Highlights.PerBookmark = float64(st.Highlights.Total) / float64(st.Total)
+		st.Missing = MissingStats{
+			Tags:        percent(noTags, st.Total),
+			Highlights:  percent(noHighlights, st.Total),
+			Description: percent(noDescription, st.Total),
+		}
+	}
+
+	return st
+}
+
+// Domain returns the host of rawURL without a leading "www.", or "" if it
+// can't be parsed.
+func Domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// uniqueTags returns the distinct lowercased tags, sorted so pairs have a
+// stable order.
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func top(counts map[string]int) []Count {
+	out := make([]Count, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, Count{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
+
+func percent(n, total int) float64 {
+	return float64(int(float64(n)*1000/float64(total)+0.5)) / 10
+}
+
+// Service computes library stats and caches them per store version.
+type Service struct {
+	store *index.Store
+
+	mu      sync.Mutex
+	version uint64
+	stats   *Stats
+}
+
+func NewService(store *index.Store) *Service {
+	return &Service{store: store}
+}
+
+// Stats returns the stats for the current index, recomputing them only if
+// the store changed since the last call.
+func (s *Service) Stats() Stats {
+	version := s.store.Version()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats != nil && s.version == version {
+		return *s.stats
+	}
+	st := Compute(s.store.Entries())
+	s.stats, s.version = &st, version
+	return st
+}
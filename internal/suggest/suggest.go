@@ -0,0 +1,132 @@
+package suggest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/stats"
+)
+
+// maxQueries bounds how many distinct past queries are remembered.
+const maxQueries = 1000
+
+// Completion is a suggested query.
+type Completion struct {
+	Text string `json:"text"`
+	Kind Kind   `json:"kind"`
+	// Count is how many bookmarks (or past searches) it came from.
+	Count int `json:"count"`
+}
+
+// Suggester completes search prefixes from a trie over bookmark titles,
+// tags and domains plus queries searched since the server started. The
+// trie is rebuilt in the background when the store changes; lookups never
+// wait for it.
+type Suggester struct {
+	store *index.Store
+
+	mu         sync.Mutex
+	trie       *trie
+	version    uint64
+	refreshing bool
+	queries    map[string]int
+}
+
+func NewSuggester(store *index.Store) *Suggester {
+	return &Suggester{store: store, queries: make(map[string]int)}
+}
+
+// Complete returns up to limit (at most MaxLimit) completions of prefix
+// (ignoring case), best first.
+func (s *Suggester) Complete(prefix string, limit int) []Completion {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []Completion{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.current()
+	items := t.complete(prefix, limit)
+	out := make([]Completion, len(items))
+	for i, it := range items {
+		out[i] = Completion{Text: it.text, Kind: it.kind, Count: it.count}
+	}
+	return out
+}
+
+// RecordQuery remembers a search so it is offered as a completion.
+func (s *Suggester) RecordQuery(query string) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queries[query]; !ok && len(s.queries) >= maxQueries {
+		return
+	}
+	s.queries[query]++
+	if s.trie != nil {
+		s.trie.add(query, KindQuery)
+	}
+}
+
+// current returns the trie, building it on first use and starting a
+// background rebuild when the store has changed. The caller must hold s.mu.
+func (s *Suggester) current() *trie {
+	version := s.store.Version()
+	if s.trie == nil {
+		s.trie = build(s.store.Entries(), s.queries)
+		s.version = version
+	} else if s.version != version && !s.refreshing {
+		s.refreshing = true
+		go s.refresh(version)
+	}
+	return s.trie
+}
+
+func (s *Suggester) refresh(version uint64) {
+	s.mu.Lock()
+	queries := make(map[string]int, len(s.queries))
+	for q, n := range s.queries {
+		queries[q] = n
+	}
+	s.mu.Unlock()
+
+	t := build(s.store.Entries(), queries)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Queries recorded during the build were only added to the old trie
+	for q, n := range s.queries {
+		for range n - queries[q] {
+			t.add(q, KindQuery)
+		}
+	}
+	s.trie = t
+	s.version = version
+	s.refreshing = false
+}
+
+// build indexes entries and past queries with their search counts.
+func build(entries []index.IndexEntry, queries map[string]int) *trie {
+	t := newTrie()
+	for _, e := range entries {
+		for _, tag := range e.Tags {
+			t.add(tag, KindTag)
+		}
+		t.add(stats.Domain(e.URL), KindDomain)
+		t.add(e.Title, KindTitle)
+	}
+	for q, n := range queries {
+		for range n {
+			t.add(q, KindQuery)
+		}
+	}
+	return t
+}
@@ -0,0 +1,195 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxLimit is the most completions Complete returns: each trie node only
+// keeps its best MaxLimit.
+const MaxLimit = topK
+
+const (
+	// topK is how many best completions each trie node keeps.
+	topK = 10
+	// maxDepth caps how many runes of a key are stored as nodes; nodes at
+	// the cap list every key below them so longer prefixes are filtered.
+	maxDepth = 32
+)
+
+// Kind says where a completion came from.
+type Kind string
+
+const (
+	KindQuery  Kind = "query"
+	KindTag    Kind = "tag"
+	KindDomain Kind = "domain"
+	KindTitle  Kind = "title"
+)
+
+// kindWeight ranks sources: past queries first, then tags, domains, titles.
+var kindWeight = map[Kind]int{KindQuery: 4, KindTag: 3, KindDomain: 2, KindTitle: 1}
+
+type item struct {
+	text  string
+	lower string
+	kind  Kind
+	count int
+}
+
+func (it item) score() int {
+	return it.count * kindWeight[it.kind]
+}
+
+type edge struct {
+	r    rune
+	node *node
+}
+
+type node struct {
+	children []edge
+	// top holds the best items in this subtree, best first.
+	top []int32
+	// all holds every item in the subtree; only set at maxDepth.
+	all []int32
+}
+
+// trie is a prefix tree of lowercased completions. Each node caches its
+// best items so a lookup is a walk down the prefix.
+type trie struct {
+	root  *node
+	items []item
+	byKey map[string]int32
+}
+
+func newTrie() *trie {
+	return &trie{root: &node{}, byKey: make(map[string]int32)}
+}
+
+// add counts one more use of text as kind. A text seen under several
+// kinds keeps the highest-ranked kind.
+func (t *trie) add(text string, kind Kind) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	lower := strings.ToLower(text)
+
+	id, ok := t.byKey[lower]
+	if !ok {
+		id = int32(len(t.items))
+		t.items = append(t.items, item{text: text, lower: lower, kind: kind})
+		t.byKey[lower] = id
+	}
+	it := &t.items[id]
+	if kindWeight[kind] > kindWeight[it.kind] {
+		it.kind, it.text = kind, text
+	}
+	it.count++
+
+	n := t.root
+	t.rank(n, id)
+	depth := 0
+	for _, r := range lower {
+		n = n.child(r)
+		depth++
+		if depth == maxDepth {
+			if !ok {
+				n.all = append(n.all, id)
+			}
+			t.rank(n, id)
+			break
+		}
+		t.rank(n, id)
+	}
+}
+
+func (n *node) child(r rune) *node {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].r >= r })
+	if i < len(n.children) && n.children[i].r == r {
+		return n.children[i].node
+	}
+	c := &node{}
+	n.children = append(n.children, edge{})
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = edge{r: r, node: c}
+	return c
+}
+
+func (n *node) find(r rune) *node {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].r >= r })
+	if i < len(n.children) && n.children[i].r == r {
+		return n.children[i].node
+	}
+	return nil
+}
+
+// rank moves id into its place in n.top after its score grew.
+func (t *trie) rank(n *node, id int32) {
+	pos := -1
+	for i, x := range n.top {
+		if x == id {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		if len(n.top) == topK && !t.better(id, n.top[topK-1]) {
+			return
+		}
+		if len(n.top) < topK {
+			n.top = append(n.top, id)
+		} else {
+			n.top[topK-1] = id
+		}
+		pos = len(n.top) - 1
+	}
+	for pos > 0 && t.better(n.top[pos], n.top[pos-1]) {
+		n.top[pos], n.top[pos-1] = n.top[pos-1], n.top[pos]
+		pos--
+	}
+}
+
+func (t *trie) better(a, b int32) bool {
+	sa, sb := t.items[a].score(), t.items[b].score()
+	if sa != sb {
+		return sa > sb
+	}
+	return t.items[a].lower < t.items[b].lower
+}
+
+// complete returns up to limit items starting with the lowercased prefix.
+func (t *trie) complete(prefix string, limit int) []item {
+	n := t.root
+	depth := 0
+	for _, r := range prefix {
+		if depth == maxDepth {
+			break
+		}
+		if n = n.find(r); n == nil {
+			return nil
+		}
+		depth++
+	}
+
+	ids := n.top
+	if depth == maxDepth {
+		// Past the stored depth: filter every key under the node
+		ids = nil
+		for _, id := range n.all {
+			if strings.HasPrefix(t.items[id].lower, prefix) {
+				ids = append(ids, id)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return t.better(ids[i], ids[j]) })
+	}
+
+	out := make([]item, 0, min(limit, len(ids)))
+	for _, id := range ids {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, t.items[id])
+	}
+	return out
+}
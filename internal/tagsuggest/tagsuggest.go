@@ -0,0 +1,198 @@
+package tagsuggest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aryannaik/curius-search/internal/index"
+)
+
+const (
+	// neighbours is how many nearest tagged bookmarks vote on tags.
+	neighbours = 15
+	// knnWeight blends the neighbour vote with tag-centroid similarity.
+	knnWeight = 0.6
+	// minTagUses is how often a tag must occur to be suggested.
+	minTagUses = 2
+)
+
+// Suggestion is a proposed tag with a confidence in [0, 1].
+type Suggestion struct {
+	Tag        string  `json:"tag"`
+	Confidence float32 `json:"confidence"`
+	// Votes is how many of the nearest tagged bookmarks carry the tag.
+	Votes int `json:"votes"`
+}
+
+// model is the tagged part of the library, prepared for scoring.
+type model struct {
+	version   uint64
+	tagged    []taggedEntry
+	centroids map[string][]float32
+	dim       int
+}
+
+type taggedEntry struct {
+	key  string
+	vec  []float32
+	tags []string
+}
+
+// Suggester proposes tags for bookmarks from their tagged neighbours.
+type Suggester struct {
+	store *index.Store
+
+	mu    sync.Mutex
+	model *model
+}
+
+func NewSuggester(store *index.Store) *Suggester {
+	return &Suggester{store: store}
+}
+
+// Suggest returns up to limit tags for entry that it doesn't already have,
+// best first. Confidence blends a similarity-weighted vote of the nearest
+// tagged bookmarks with the entry's similarity to each tag's centroid.
+func (s *Suggester) Suggest(entry index.IndexEntry, limit int) []Suggestion {
+	m := s.current()
+	vec := index.Normalize(entry.Embedding)
+	if vec == nil || len(vec) != m.dim || len(m.tagged) == 0 {
+		return nil
+	}
+
+	have := make(map[string]bool, len(entry.Tags))
+	for _, t := range entry.Tags {
+		have[t] = true
+	}
+	self := entry.Key()
+
+	// Nearest tagged neighbours
+	type neighbour struct {
+		sim  float32
+		tags []string
+	}
+	nearest := make([]neighbour, 0, len(m.tagged))
+	for _, t := range m.tagged {
+		if t.key == self {
+			continue
+		}
+		nearest = append(nearest, neighbour{index.Dot(vec, t.vec), t.tags})
+	}
+	sort.Slice(nearest, func(i, j int) bool { return nearest[i].sim > nearest[j].sim })
+	if len(nearest) > neighbours {
+		nearest = nearest[:neighbours]
+	}
+
+	var totalSim float32
+	votes := make(map[string]float32)
+	counts := make(map[string]int)
+	for _, n := range nearest {
+		sim := max(n.sim, 0)
+		totalSim += sim
+		for _, t := range n.tags {
+			votes[t] += sim
+			counts[t]++
+		}
+	}
+
+	var out []Suggestion
+	for tag, centroid := range m.centroids {
+		if have[tag] {
+			continue
+		}
+		var knn float32
+		if totalSim > 0 {
+			knn = votes[tag] / totalSim
+		}
+		cent := max(index.Dot(vec, centroid), 0)
+		conf := knnWeight*knn + (1-knnWeight)*cent
+		if counts[tag] == 0 {
+			// Without a single nearby vote the centroid alone is weak evidence
+			conf *= 0.5
+		}
+		out = append(out, Suggestion{Tag: tag, Confidence: conf, Votes: counts[tag]})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Confidence != out[j].Confidence {
+			return out[i].Confidence > out[j].Confidence
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// current returns the model for the store's current version, rebuilding it if stale.
+func (s *Suggester) current() *model {
+	version := s.store.Version()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model != nil && s.model.version == version {
+		return s.model
+	}
+	s.model = build(s.store.Entries(), version)
+	return s.model
+}
+
+func build(entries []index.IndexEntry, version uint64) *model {
+	m := &model{version: version, centroids: make(map[string][]float32)}
+
+	uses := make(map[string]int)
+	dims := make(map[int]int)
+	for _, e := range entries {
+		for _, t := range e.Tags {
+			uses[t]++
+		}
+		if len(e.Tags) > 0 && len(e.Embedding) > 0 {
+			dims[len(e.Embedding)]++
+		}
+	}
+	for d, c := range dims {
+		if c > dims[m.dim] {
+			m.dim = d
+		}
+	}
+
+	sums := make(map[string][]float64)
+	for _, e := range entries {
+		if len(e.Tags) == 0 || len(e.Embedding) != m.dim {
+			continue
+		}
+		vec := index.Normalize(e.Embedding)
+		if vec == nil {
+			continue
+		}
+
+		var tags []string
+		for _, t := range e.Tags {
+			if uses[t] < minTagUses {
+				continue
+			}
+			tags = append(tags, t)
+			if sums[t] == nil {
+				sums[t] = make([]float64, m.dim)
+			}
+			for i, x := range vec {
+				sums[t][i] += float64(x)
+			}
+		}
+		if len(tags) > 0 {
+			m.tagged = append(m.tagged, taggedEntry{key: e.Key(), vec: vec, tags: tags})
+		}
+	}
+
+	for t, sum := range sums {
+		c := make([]float32, len(sum))
+		for i, x := range sum {
+			c[i] = float32(x)
+		}
+		if c = index.Normalize(c); c != nil {
+			m.centroids[t] = c
+		}
+	}
+	return m
+}
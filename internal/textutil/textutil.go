@@ -0,0 +1,11 @@
+// Package textutil holds small string helpers shared by the CLI and exporters.
+package textutil
+
+// Truncate shortens s to at most n runes, ending it with "..." if it was cut.
+func Truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyPgUp
+	keyPgDown
+	keyEnter
+	keyBackspace
+	keyEsc
+	keyTab
+	keyCtrlC
+	keyCtrlO
+	keyCtrlS
+	keyCtrlU
+	keyCtrlY
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// readKeys decodes terminal input into keys until r fails. Escape
+// sequences are recognised when they arrive in a single read, which is
+// how terminals send them; a lone ESC is the escape key.
+func readKeys(r io.Reader, keys chan<- key) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, 256)
+	for {
+		n, err := br.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+func parseKeys(b []byte) []key {
+	var out []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				switch b[2] {
+				case 'A':
+					out = append(out, key{code: keyUp})
+				case 'B':
+					out = append(out, key{code: keyDown})
+				case '5', '6':
+					// PgUp/PgDown are ESC [ 5 ~ and ESC [ 6 ~
+					if len(b) >= 4 && b[3] == '~' {
+						if b[2] == '5' {
+							out = append(out, key{code: keyPgUp})
+						} else {
+							out = append(out, key{code: keyPgDown})
+						}
+						b = b[4:]
+						continue
+					}
+				}
+				// Skip the rest of an unknown sequence up to its final byte
+				i := 2
+				for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+					i++
+				}
+				b = b[min(i+1, len(b)):]
+				continue
+			}
+			out = append(out, key{code: keyEsc})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			out = append(out, key{code: keyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			out = append(out, key{code: keyBackspace})
+			b = b[1:]
+		case c == '\t':
+			out = append(out, key{code: keyTab})
+			b = b[1:]
+		case c == 0x03:
+			out = append(out, key{code: keyCtrlC})
+			b = b[1:]
+		case c == 0x0f:
+			out = append(out, key{code: keyCtrlO})
+			b = b[1:]
+		case c == 0x13:
+			out = append(out, key{code: keyCtrlS})
+			b = b[1:]
+		case c == 0x15:
+			out = append(out, key{code: keyCtrlU})
+			b = b[1:]
+		case c == 0x19:
+			out = append(out, key{code: keyCtrlY})
+			b = b[1:]
+		case c == 0x10:
+			out = append(out, key{code: keyUp})
+			b = b[1:]
+		case c == 0x0e:
+			out = append(out, key{code: keyDown})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			out = append(out, key{code: keyRune, r: r})
+			b = b[size:]
+		}
+	}
+	return out
+}
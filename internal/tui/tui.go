@@ -0,0 +1,484 @@
+// Package tui is a full-screen terminal interface for searching the local
+// index: search-as-you-type, a result list with a preview pane, and
+// keybindings to open, copy or pivot to similar bookmarks.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/search"
+)
+
+const (
+	resultLimit = 30
+	debounce    = 200 * time.Millisecond
+	prompt      = "Search: "
+	helpLine    = "↑/↓ move  enter open  ^Y copy URL  ^S similar  esc back  ^C quit"
+)
+
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+)
+
+// view is one list of results: the query results at the bottom of the
+// stack, and a "similar to" pivot above it for each ^S.
+type view struct {
+	title    string
+	results  []search.Result
+	selected int
+	offset   int
+}
+
+func (v *view) current() *search.Result {
+	if v.selected < 0 || v.selected >= len(v.results) {
+		return nil
+	}
+	return &v.results[v.selected]
+}
+
+type resultsMsg struct {
+	seq     int
+	results []search.Result
+	took    time.Duration
+	err     error
+}
+
+type ui struct {
+	searcher *search.Searcher
+	filter   index.Filter
+	out      io.Writer
+	fd       int
+
+	width, height int
+	query         string
+	views         []view
+	status        string
+	statusErr     bool
+	seq           int
+	searching     bool
+	msgs          chan resultsMsg
+}
+
+// Run takes over the terminal on in/out until the user quits. Searches
+// are scoped by filter.
+func Run(searcher *search.Searcher, filter index.Filter, in, out *os.File) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("stdin is not a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("entering raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// Log lines from the search path would tear the screen
+	logOut := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(logOut)
+
+	fmt.Fprint(out, "\x1b[?1049h")
+	defer fmt.Fprint(out, "\x1b[2J\x1b[?25h\x1b[?1049l")
+
+	u := &ui{
+		searcher: searcher,
+		filter:   filter,
+		out:      out,
+		fd:       int(out.Fd()),
+		views:    []view{{}},
+		status:   "Type to search; operators like tag:rust work too",
+		msgs:     make(chan resultsMsg, 16),
+	}
+	u.resize()
+
+	keys := make(chan key, 16)
+	go readKeys(in, keys)
+
+	// Poll for resizes rather than SIGWINCH, which Windows lacks
+	resizeTick := time.NewTicker(250 * time.Millisecond)
+	defer resizeTick.Stop()
+
+	var timer *time.Timer
+	var pending <-chan time.Time
+
+	u.draw()
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			before := u.query
+			if quit := u.handleKey(k); quit {
+				return nil
+			}
+			if u.query != before {
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.NewTimer(debounce)
+				pending = timer.C
+			}
+		case <-pending:
+			pending = nil
+			u.startSearch()
+		case m := <-u.msgs:
+			if m.seq != u.seq {
+				continue
+			}
+			u.searching = false
+			u.views[0] = view{results: m.results}
+			if m.err != nil {
+				u.fail("Search failed: " + m.err.Error())
+			} else {
+				u.setStatus(fmt.Sprintf("%d results · %dms", len(m.results), m.took.Milliseconds()))
+			}
+		case <-resizeTick.C:
+			if !u.resize() {
+				continue
+			}
+		}
+		u.draw()
+	}
+}
+
+// resize re-reads the terminal size and reports whether it changed.
+func (u *ui) resize() bool {
+	w, h, err := term.GetSize(u.fd)
+	if err != nil {
+		w, h = 80, 24
+	}
+	if w == u.width && h == u.height {
+		return false
+	}
+	u.width, u.height = w, h
+	return true
+}
+
+func (u *ui) top() *view {
+	return &u.views[len(u.views)-1]
+}
+
+// fail shows an error in the status line until the next status.
+func (u *ui) fail(msg string) {
+	u.status, u.statusErr = msg, true
+}
+
+func (u *ui) setStatus(msg string) {
+	u.status, u.statusErr = msg, false
+}
+
+// handleKey applies one key press and reports whether to quit.
+func (u *ui) handleKey(k key) bool {
+	switch k.code {
+	case keyCtrlC:
+		return true
+	case keyEsc:
+		switch {
+		case len(u.views) > 1:
+			u.views = u.views[:len(u.views)-1]
+			u.setStatus("")
+		case u.query != "":
+			u.setQuery("")
+		default:
+			return true
+		}
+	case keyRune:
+		u.setQuery(u.query + string(k.r))
+	case keyBackspace:
+		if u.query != "" {
+			_, size := utf8.DecodeLastRuneInString(u.query)
+			u.setQuery(u.query[:len(u.query)-size])
+		}
+	case keyCtrlU:
+		u.setQuery("")
+	case keyUp:
+		u.move(-1)
+	case keyDown:
+		u.move(1)
+	case keyPgUp:
+		u.move(-u.listHeight())
+	case keyPgDown:
+		u.move(u.listHeight())
+	case keyEnter, keyCtrlO:
+		if r := u.top().current(); r != nil {
+			if err := openURL(r.URL); err != nil {
+				u.fail("Open failed: " + err.Error())
+			} else {
+				u.setStatus("Opened " + r.URL)
+			}
+		}
+	case keyCtrlY:
+		if r := u.top().current(); r != nil {
+			how, err := copyToClipboard(r.URL, u.out)
+			if err != nil {
+				u.fail("Copy failed: " + err.Error())
+			} else {
+				u.setStatus("Copied URL (" + how + ")")
+			}
+		}
+	case keyCtrlS, keyTab:
+		u.similar()
+	}
+	return false
+}
+
+// setQuery edits the query. Typing always returns to the query results.
+func (u *ui) setQuery(q string) {
+	u.query = q
+	u.views = u.views[:1]
+}
+
+func (u *ui) move(delta int) {
+	v := u.top()
+	if len(v.results) == 0 {
+		return
+	}
+	v.selected = min(max(v.selected+delta, 0), len(v.results)-1)
+}
+
+// startSearch runs the current query in the background. Results of
+// superseded queries are dropped by sequence number.
+func (u *ui) startSearch() {
+	u.seq++
+	seq, query := u.seq, strings.TrimSpace(u.query)
+	if query == "" {
+		u.searching = false
+		u.views[0] = view{}
+		u.setStatus("")
+		return
+	}
+
+	u.searching = true
+	go func() {
+		start := time.Now()
+		results, err := u.searcher.Search(query, resultLimit, u.filter)
+		u.msgs <- resultsMsg{seq: seq, results: results, took: time.Since(start), err: err}
+	}()
+}
+
+// similar pushes a view of the bookmarks most similar to the selected one.
+// This only compares stored vectors, so it runs inline.
+func (u *ui) similar() {
+	r := u.top().current()
+	if r == nil {
+		return
+	}
+	results, err := u.searcher.FindSimilar(r.Key, resultLimit, u.filter)
+	if err != nil {
+		u.fail("Find similar failed: " + err.Error())
+		return
+	}
+	u.views = append(u.views, view{title: displayTitle(*r), results: results})
+	u.setStatus("")
+}
+
+// listHeight is the number of rows available for results and preview.
+func (u *ui) listHeight() int {
+	return max(u.height-4, 1)
+}
+
+func (u *ui) draw() {
+	var b strings.Builder
+	b.WriteString("\x1b[?25l\x1b[H")
+
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteString(styleReset + "\x1b[K\r\n")
+	}
+
+	line(styleBold + prompt + styleReset + u.query)
+
+	v := u.top()
+	status, statusStyle := u.status, styleDim
+	if u.statusErr {
+		statusStyle = styleRed
+	}
+	switch {
+	case u.searching:
+		status, statusStyle = "Searching…", styleDim
+	case v.title != "" && status == "":
+		status = fmt.Sprintf("%d similar to %q · esc to go back", len(v.results), v.title)
+	}
+	line(statusStyle + fit(status, u.width))
+	line(styleDim + strings.Repeat("─", u.width))
+
+	rows := u.listHeight()
+	listW, previewW := u.width, 0
+	if u.width >= 60 {
+		listW = u.width * 2 / 5
+		previewW = u.width - listW - 3
+	}
+
+	// Keep the selection on screen
+	if v.selected < v.offset {
+		v.offset = v.selected
+	} else if v.selected >= v.offset+rows {
+		v.offset = v.selected - rows + 1
+	}
+
+	var preview []string
+	if r := v.current(); r != nil && previewW > 0 {
+		preview = previewLines(*r, previewW)
+	}
+
+	for i := 0; i < rows; i++ {
+		var row string
+		if n := v.offset + i; n < len(v.results) {
+			r := v.results[n]
+			text := displayTitle(r)
+			if r.Dead {
+				text = "✗ " + text
+			}
+			text = fit(" "+text, listW)
+			if n == v.selected {
+				text = styleReverse + text + styleReset
+			}
+			row = text
+		} else {
+			row = strings.Repeat(" ", listW)
+		}
+		if previewW > 0 {
+			row += styleDim + " │ " + styleReset
+			if i < len(preview) {
+				row += preview[i]
+			}
+		}
+		if i == rows-1 {
+			b.WriteString(row + styleReset + "\x1b[K\r\n")
+		} else {
+			line(row)
+		}
+	}
+
+	b.WriteString(styleDim + fit(helpLine, u.width) + styleReset + "\x1b[K")
+
+	// Park the cursor at the end of the query
+	col := utf8.RuneCountInString(prompt+u.query) + 1
+	fmt.Fprintf(&b, "\x1b[1;%dH\x1b[?25h", min(col, u.width))
+	io.WriteString(u.out, b.String())
+}
+
+// previewLines renders the selected bookmark for the preview pane.
+func previewLines(r search.Result, width int) []string {
+	var lines []string
+	add := func(style, s string) {
+		for _, l := range wrap(s, width) {
+			lines = append(lines, style+l+styleReset)
+		}
+	}
+
+	add(styleBold, displayTitle(r))
+	add(styleDim, r.URL)
+	lines = append(lines, "")
+
+	meta := []string{"saved " + r.CreatedAt}
+	if r.UserID != "" {
+		meta = append(meta, "user "+r.UserID)
+	}
+	if r.Source != "" && r.Source != "curius" {
+		meta = append(meta, r.Source)
+	}
+	if r.Network {
+		meta = append(meta, "network")
+	}
+	add("", strings.Join(meta, " · "))
+	if len(r.Tags) > 0 {
+		add("", "Tags: "+strings.Join(r.Tags, ", "))
+	}
+	if r.Dead {
+		dead := "Dead link"
+		if r.StatusCode != 0 {
+			dead += fmt.Sprintf(" (%d)", r.StatusCode)
+		}
+		add(styleRed, dead)
+		if r.ArchiveURL != "" {
+			add(styleDim, "Archive: "+r.ArchiveURL)
+		}
+	}
+	for _, u := range r.AlsoSavedAs {
+		add(styleDim, "Also saved as "+u)
+	}
+
+	if r.Snippet != "" {
+		lines = append(lines, "")
+		add("", r.Snippet)
+	}
+	if len(r.Highlights) > 0 {
+		lines = append(lines, "", styleBold+"Highlights"+styleReset)
+		for _, h := range r.Highlights {
+			for _, l := range wrap(h, width-2) {
+				lines = append(lines, styleDim+"│ "+styleReset+l)
+			}
+		}
+	}
+	return lines
+}
+
+func displayTitle(r search.Result) string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return r.URL
+}
+
+// fit truncates or pads s to exactly width runes.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// wrap breaks s into lines of at most width runes, splitting on spaces
+// and hard-breaking words longer than a line.
+func wrap(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	var cur []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		for len(w) > width {
+			if len(cur) > 0 {
+				lines = append(lines, string(cur))
+				cur = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(cur) == 0:
+			cur = w
+		case len(cur)+1+len(w) <= width:
+			cur = append(append(cur, ' '), w...)
+		default:
+			lines = append(lines, string(cur))
+			cur = w
+		}
+	}
+	if len(cur) > 0 {
+		lines = append(lines, string(cur))
+	}
+	return lines
+}
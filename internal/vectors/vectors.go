@@ -0,0 +1,290 @@
+// Package vectors reads embeddings exported from another index (see
+// package export), so a shared prebuilt index can be imported and only the
+// bookmarks it lacks need embedding.
+package vectors
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryannaik/curius-search/internal/export"
+	"github.com/aryannaik/curius-search/internal/index"
+	"github.com/aryannaik/curius-search/internal/source"
+)
+
+// Vector is one imported embedding.
+type Vector struct {
+	// Key identifies the bookmark, as index.EntryKey.
+	Key       string
+	UserID    string
+	Model     string
+	Embedding []float32
+	// Entry is the bookmark itself when the file describes it (a JSONL
+	// export with titles and URLs), so it can be indexed right away.
+	Entry *index.IndexEntry
+}
+
+// jsonlLine is a line of a vectors JSONL file: an export record, or just
+// an ID (or key) and a "vector".
+type jsonlLine struct {
+	export.Record
+	Key    string    `json:"key"`
+	Vector []float32 `json:"vector"`
+}
+
+// maxLine bounds a JSONL line; a 4096-dim vector is about 50 KB.
+const maxLine = 16 << 20
+
+// ReadJSONL reads one JSON object per line holding a bookmark "id" (or
+// "key") and its "embedding" (or "vector"), optionally with "source",
+// "userId", "model" and the rest of an export record. Lines without a user
+// belong to userID.
+func ReadJSONL(r io.Reader, userID string) ([]Vector, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLine)
+
+	var vecs []Vector
+	for n := 1; sc.Scan(); n++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var l jsonlLine
+		if err := json.Unmarshal([]byte(text), &l); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		emb := l.Embedding
+		if len(emb) == 0 {
+			emb = l.Vector
+		}
+		if len(emb) == 0 {
+			return nil, fmt.Errorf("line %d: no embedding or vector", n)
+		}
+
+		src, user, id := l.Source, l.UserID, l.ID
+		if l.Key != "" {
+			var err error
+			if src, user, id, err = ParseKey(l.Key, userID); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("line %d: no id or key", n)
+		}
+		if src == "" {
+			src = source.NameCurius
+		}
+		if user == "" {
+			user = userID
+		}
+		if user == "" {
+			return nil, fmt.Errorf("line %d: no userId for bookmark %d", n, id)
+		}
+
+		v := Vector{
+			Key:       index.EntryKey(src, user, id),
+			UserID:    user,
+			Model:     l.Model,
+			Embedding: emb,
+		}
+		if l.URL != "" {
+			v.Entry = &index.IndexEntry{
+				ID:          id,
+				Source:      src,
+				UserID:      user,
+				Network:     l.Network,
+				Title:       l.Title,
+				URL:         l.URL,
+				Highlights:  l.Highlights,
+				Tags:        l.Tags,
+				Description: l.Description,
+				CreatedAt:   l.CreatedAt,
+				IndexedAt:   time.Now(),
+				Embedding:   emb,
+			}
+		}
+		vecs = append(vecs, v)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read vectors: %w", err)
+	}
+	return vecs, nil
+}
+
+// ReadNPY reads a float32 or float64 (rows, dim) .npy matrix and its ID
+// sidecar: a key ("source/user/id", as export writes) or a bare Curius
+// bookmark ID per line, one per row. Bare IDs belong to userID. The file
+// carries no model, so model is recorded on every vector.
+func ReadNPY(npy, ids io.Reader, userID, model string) ([]Vector, error) {
+	br := bufio.NewReader(npy)
+	rows, dim, wide, err := readNPYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := readIDs(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != rows {
+		return nil, fmt.Errorf("matrix has %d rows but the ID file has %d lines", rows, len(keys))
+	}
+
+	size := 4
+	if wide {
+		size = 8
+	}
+	buf := make([]byte, size*dim)
+	vecs := make([]Vector, 0, rows)
+	for _, k := range keys {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("read matrix row %d: %w", len(vecs), err)
+		}
+		emb := make([]float32, dim)
+		for i := range emb {
+			if wide {
+				emb[i] = float32(math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:])))
+			} else {
+				emb[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+			}
+		}
+		vecs = append(vecs, Vector{Key: k.key, UserID: k.user, Model: model, Embedding: emb})
+	}
+	return vecs, nil
+}
+
+// readNPYHeader reads the preamble of a little-endian, C-order 2-D .npy
+// array and returns its shape and whether it holds float64.
+func readNPYHeader(r io.Reader) (rows, dim int, wide bool, err error) {
+	pre := make([]byte, 8)
+	if _, err := io.ReadFull(r, pre); err != nil || string(pre[:6]) != "\x93NUMPY" {
+		return 0, 0, false, errors.New("not an .npy file")
+	}
+
+	var n int
+	switch pre[6] {
+	case 1:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, 0, false, fmt.Errorf("read .npy header: %w", err)
+		}
+		n = int(binary.LittleEndian.Uint16(b))
+	case 2, 3:
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, 0, false, fmt.Errorf("read .npy header: %w", err)
+		}
+		n = int(binary.LittleEndian.Uint32(b))
+	default:
+		return 0, 0, false, fmt.Errorf("unsupported .npy version %d", pre[6])
+	}
+	if n > 1<<20 {
+		return 0, 0, false, errors.New("invalid .npy header")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, 0, false, fmt.Errorf("read .npy header: %w", err)
+	}
+	header := string(b)
+
+	switch {
+	case strings.Contains(header, "'<f4'"):
+	case strings.Contains(header, "'<f8'"):
+		wide = true
+	default:
+		return 0, 0, false, fmt.Errorf("unsupported .npy dtype in %s: want little-endian float32 or float64", strings.TrimSpace(header))
+	}
+	if strings.Contains(header, "'fortran_order': True") {
+		return 0, 0, false, errors.New("unsupported Fortran-order .npy matrix")
+	}
+
+	_, shape, ok := strings.Cut(header, "'shape': (")
+	shape, _, ok2 := strings.Cut(shape, ")")
+	dims := strings.Split(strings.ReplaceAll(shape, " ", ""), ",")
+	if !ok || !ok2 || len(dims) != 2 {
+		return 0, 0, false, fmt.Errorf("want a 2-D (rows, dim) matrix, got shape (%s)", shape)
+	}
+	rows, err1 := strconv.Atoi(dims[0])
+	dim, err2 := strconv.Atoi(dims[1])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false, fmt.Errorf("invalid .npy shape (%s)", shape)
+	}
+	return rows, dim, wide, nil
+}
+
+type rowKey struct {
+	key, user string
+}
+
+func readIDs(r io.Reader, userID string) ([]rowKey, error) {
+	var keys []rowKey
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		src, user, id, err := ParseKey(line, userID)
+		if err != nil {
+			return nil, fmt.Errorf("ID file line %d: %w", n, err)
+		}
+		keys = append(keys, rowKey{index.EntryKey(src, user, id), user})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read ID file: %w", err)
+	}
+	return keys, nil
+}
+
+// ParseKey splits an entry key ("source/user/id") into its parts. A bare
+// ID is a Curius bookmark of userID.
+func ParseKey(s, userID string) (src, user string, id int, err error) {
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 1:
+		if userID == "" {
+			return "", "", 0, fmt.Errorf("no user for bare ID %q", s)
+		}
+		src, user = source.NameCurius, userID
+	case 3:
+		src, user = parts[0], parts[1]
+	default:
+		return "", "", 0, fmt.Errorf("invalid key %q: want source/user/id or an ID", s)
+	}
+	id, err = strconv.Atoi(parts[len(parts)-1])
+	if err != nil || id == 0 {
+		return "", "", 0, fmt.Errorf("invalid key %q: bad ID", s)
+	}
+	if src == "" {
+		src = source.NameCurius
+	}
+	return src, user, id, nil
+}
+
+// Validate checks that the vectors are finite, share one dimension and,
+// if dim is not 0, have that dimension.
+func Validate(vecs []Vector, dim int) error {
+	for _, v := range vecs {
+		if dim == 0 {
+			dim = len(v.Embedding)
+		}
+		if len(v.Embedding) != dim {
+			return fmt.Errorf("%s: embedding has %d dims, want %d", v.Key, len(v.Embedding), dim)
+		}
+		for _, x := range v.Embedding {
+			if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+				return fmt.Errorf("%s: embedding has non-finite values", v.Key)
+			}
+		}
+	}
+	return nil
+}
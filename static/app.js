@@ -2,21 +2,40 @@ const input = document.getElementById("search-input");
 const resultsEl = document.getElementById("results");
 const statusEl = document.getElementById("status");
 const historyDropdown = document.getElementById("history-dropdown");
+const userFilter = document.getElementById("user-filter");
+const networkFilter = document.getElementById("network-filter");
+const sourceFilter = document.getElementById("source-filter");
+const saveSearchBtn = document.getElementById("save-search");
 
 let debounceTimer = null;
 let historyIndex = -1;
+let multiUser = false;
+let clusterLabels = {};
+let savedSearches = [];
+let suggestSeq = 0;
 
 const HISTORY_KEY = "curius-search-history";
 const MAX_HISTORY = 20;
 
 // Load status on page load
 fetchStatus();
+fetchClusters();
+fetchSavedSearches();
+
+// Run a query passed in the URL (e.g. from the server-rendered /search page)
+const initialQuery = new URLSearchParams(location.search).get("q");
+if (initialQuery) {
+    input.value = initialQuery;
+    saveSearchBtn.classList.remove("hidden");
+    doSearch(initialQuery);
+}
 
 input.addEventListener("input", () => {
     clearTimeout(debounceTimer);
     hideHistory();
     const query = input.value.trim();
 
+    saveSearchBtn.classList.toggle("hidden", !query);
     if (!query) {
         resultsEl.innerHTML = "";
         statusEl.textContent = "";
@@ -24,6 +43,7 @@ input.addEventListener("input", () => {
     }
 
     statusEl.textContent = "Searching...";
+    showSuggestions(query);
 
     debounceTimer = setTimeout(() => {
         doSearch(query);
@@ -57,13 +77,25 @@ input.addEventListener("keydown", (e) => {
         updateHistoryHighlight(items);
     } else if (e.key === "Enter" && historyIndex >= 0) {
         e.preventDefault();
-        const query = items[historyIndex].dataset.query;
-        input.value = query;
-        hideHistory();
-        doSearch(query);
+        const item = items[historyIndex];
+        if (item.dataset.saved) {
+            hideHistory();
+            openSavedSearch(Number(item.dataset.saved));
+            return;
+        }
+        selectHistory(item.dataset.query);
     }
 });
 
+for (const el of [userFilter, networkFilter, sourceFilter]) {
+    el.addEventListener("change", () => {
+        const query = input.value.trim();
+        if (query) doSearch(query);
+    });
+}
+
+saveSearchBtn.addEventListener("click", saveCurrentSearch);
+
 // Ctrl/Cmd+K to focus search
 document.addEventListener("keydown", (e) => {
     if ((e.metaKey || e.ctrlKey) && e.key === "k") {
@@ -78,14 +110,18 @@ document.addEventListener("keydown", (e) => {
 
 async function doSearch(query) {
     try {
-        const resp = await fetch(`/api/search?q=${encodeURIComponent(query)}&limit=20`);
+        const params = new URLSearchParams({ q: query, limit: "20" });
+        if (userFilter.value) params.set("user", userFilter.value);
+        if (networkFilter.value !== "include") params.set("network", networkFilter.value);
+        if (sourceFilter.value) params.set("source", sourceFilter.value);
+        const resp = await fetch(`/api/search?${params}`);
         if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
         const data = await resp.json();
 
         addToHistory(query);
 
         if (data.results && data.results.length > 0) {
-            statusEl.textContent = `${data.total} results`;
+            statusEl.textContent = `${data.total} results` + (data.indexing ? " · indexing in progress, results may be incomplete" : "");
             renderResults(data.results);
         } else {
             statusEl.textContent = "No results found";
@@ -97,10 +133,10 @@ async function doSearch(query) {
     }
 }
 
-async function doFindSimilar(id, title) {
+async function doFindSimilar(key, title) {
     statusEl.textContent = "Finding similar...";
     try {
-        const resp = await fetch(`/api/similar?id=${id}&limit=10`);
+        const resp = await fetch(`/api/similar?${new URLSearchParams({ key, limit: "10" })}`);
         if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
         const data = await resp.json();
 
@@ -119,6 +155,22 @@ async function doFindSimilar(id, title) {
     }
 }
 
+async function suggestTags(button, id, key) {
+    button.disabled = true;
+    try {
+        const resp = await fetch(`/api/bookmarks/${id}/suggested-tags?${new URLSearchParams({ key, limit: "3" })}`);
+        if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+        const data = await resp.json();
+        const pills = (data.suggestions || [])
+            .map((s) => `<span class="tag-pill tag-suggested" title="${(s.confidence * 100).toFixed(0)}% confidence">${escapeHtml(s.tag)}?</span>`)
+            .join("");
+        button.outerHTML = pills || `<span class="result-owner">no suggestions</span>`;
+    } catch (err) {
+        button.disabled = false;
+        statusEl.textContent = `Error: ${err.message}`;
+    }
+}
+
 function backToSearch() {
     const query = input.value.trim();
     if (query) {
@@ -149,14 +201,21 @@ function renderResultCards(results) {
             <div class="result-card">
                 <div class="result-header">
                     <span class="result-title"><a href="${escapeAttr(r.url)}" target="_blank" rel="noopener">${escapeHtml(r.title || "Untitled")}</a></span>
+                    ${r.network ? `<span class="network-badge">network</span>` : ""}
+                    ${r.source && r.source !== "curius" ? `<span class="network-badge">${escapeHtml(r.source)}</span>` : ""}
+                    ${r.dead ? `<span class="dead-badge" title="${r.statusCode ? `HTTP ${r.statusCode}` : "unreachable"}">dead</span>` : ""}
                     <span class="score-badge">${score}%</span>
                 </div>
-                <div class="result-url">${escapeHtml(domain)}</div>
+                <div class="result-url">${escapeHtml(domain)}${r.archiveUrl ? ` · <a href="${escapeAttr(r.archiveUrl)}" target="_blank" rel="noopener">archived copy</a>` : ""}</div>
                 ${r.snippet ? `<div class="result-snippet">${escapeHtml(r.snippet)}</div>` : ""}
                 ${highlights ? `<div class="result-highlights">${highlights}</div>` : ""}
+                ${r.alsoSavedAs ? `<div class="result-also">Also saved as: ${r.alsoSavedAs.map((u) => `<a href="${escapeAttr(u)}" target="_blank" rel="noopener">${escapeHtml(extractDomain(u))}</a>`).join(", ")}</div>` : ""}
                 <div class="result-meta">
                     ${tags}
-                    <button class="btn-similar" onclick="doFindSimilar(${r.id}, ${escapeAttr(JSON.stringify(r.title || 'Untitled'))})">Find similar</button>
+                    ${r.cluster && clusterLabels[r.cluster] ? `<button class="cluster-pill" onclick="searchCluster(${r.cluster})" title="Topic cluster">${escapeHtml(clusterLabels[r.cluster])}</button>` : ""}
+                    ${multiUser && r.userId ? `<span class="result-owner">saved by ${escapeHtml(r.userId)}</span>` : ""}
+                    ${tags ? "" : `<button class="btn-similar" onclick="suggestTags(this, ${r.id}, ${escapeAttr(JSON.stringify(r.key))})">Suggest tags</button>`}
+                    <button class="btn-similar" onclick="doFindSimilar(${escapeAttr(JSON.stringify(r.key))}, ${escapeAttr(JSON.stringify(r.title || 'Untitled'))})">Find similar</button>
                     ${r.createdAt ? `<span class="result-date">${r.createdAt}</span>` : ""}
                 </div>
             </div>
@@ -191,20 +250,32 @@ function removeFromHistory(query) {
 
 function showHistory() {
     const history = getHistory();
-    if (history.length === 0) {
+    if (history.length === 0 && savedSearches.length === 0) {
         hideHistory();
         return;
     }
 
     historyIndex = -1;
-    historyDropdown.innerHTML =
-        `<div class="history-label">Recent searches</div>` +
-        history.map((q) =>
-            `<div class="history-item" data-query="${escapeAttr(q)}" onmousedown="selectHistory('${escapeAttr(q)}')">
-                <span>${escapeHtml(q)}</span>
-                <span class="history-remove" onmousedown="event.stopPropagation(); removeFromHistory('${escapeAttr(q)}')">&times;</span>
-            </div>`
-        ).join("");
+    let html = "";
+    if (savedSearches.length > 0) {
+        html += `<div class="history-label">Saved searches</div>` +
+            savedSearches.map((s) =>
+                `<div class="history-item" data-saved="${s.id}" onmousedown="openSavedSearch(${s.id})">
+                    <span>${escapeHtml(s.name)} <span class="result-owner">${escapeHtml(s.mode === "similar" ? "similar" : s.query)}</span></span>
+                    <span class="history-remove" onmousedown="event.stopPropagation(); deleteSavedSearch(${s.id})">&times;</span>
+                </div>`
+            ).join("");
+    }
+    if (history.length > 0) {
+        html += `<div class="history-label">Recent searches</div>` +
+            history.map((q) =>
+                `<div class="history-item" data-query="${escapeAttr(q)}" onmousedown="selectHistory('${escapeAttr(q)}')">
+                    <span>${escapeHtml(q)}</span>
+                    <span class="history-remove" onmousedown="event.stopPropagation(); removeFromHistory('${escapeAttr(q)}')">&times;</span>
+                </div>`
+            ).join("");
+    }
+    historyDropdown.innerHTML = html;
 
     historyDropdown.classList.remove("hidden");
 }
@@ -215,6 +286,8 @@ function hideHistory() {
 }
 
 function selectHistory(query) {
+    clearTimeout(debounceTimer);
+    suggestSeq++;
     input.value = query;
     hideHistory();
     doSearch(query);
@@ -226,8 +299,122 @@ function updateHistoryHighlight(items) {
     });
 }
 
+// --- Typeahead ---
+
+// showSuggestions lists completions for the text typed so far. They come
+// from a prefix index on the server, so this runs on every keystroke.
+async function showSuggestions(prefix) {
+    const seq = ++suggestSeq;
+    try {
+        const resp = await fetch(`/api/suggest?${new URLSearchParams({ prefix, limit: "6" })}`);
+        if (!resp.ok || seq !== suggestSeq) return;
+        const data = await resp.json();
+        const items = (data.completions || []).filter((c) => c.text.toLowerCase() !== prefix.toLowerCase());
+        if (seq !== suggestSeq || items.length === 0 || input.value.trim() !== prefix) {
+            if (seq === suggestSeq) hideHistory();
+            return;
+        }
+
+        historyIndex = -1;
+        historyDropdown.innerHTML =
+            `<div class="history-label">Suggestions</div>` +
+            items.map((c) =>
+                `<div class="history-item" data-query="${escapeAttr(c.text)}" onmousedown="selectHistory(this.dataset.query)">
+                    <span>${escapeHtml(c.text)}</span>
+                    <span class="result-owner">${escapeHtml(c.kind)}</span>
+                </div>`
+            ).join("");
+        historyDropdown.classList.remove("hidden");
+    } catch {
+        // Ignore
+    }
+}
+
+// --- Saved Searches ---
+
+async function fetchSavedSearches() {
+    try {
+        const resp = await fetch("/api/saved-searches");
+        if (!resp.ok) return;
+        const data = await resp.json();
+        savedSearches = data.searches || [];
+    } catch {
+        // Ignore
+    }
+}
+
+async function saveCurrentSearch() {
+    const query = input.value.trim();
+    if (!query) return;
+    const name = prompt("Name this search:", query);
+    if (!name) return;
+
+    const body = { name, mode: "search", query };
+    if (userFilter.value) body.user = userFilter.value;
+    if (networkFilter.value !== "include") body.network = networkFilter.value;
+    if (sourceFilter.value) body.source = sourceFilter.value;
+
+    try {
+        const resp = await fetch("/api/saved-searches", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify(body),
+        });
+        const data = await resp.json();
+        if (!resp.ok) throw new Error(data.error || `HTTP ${resp.status}`);
+        statusEl.textContent = `Saved "${data.name}"`;
+        fetchSavedSearches();
+    } catch (err) {
+        statusEl.textContent = `Error: ${err.message}`;
+    }
+}
+
+async function deleteSavedSearch(id) {
+    try {
+        await fetch(`/api/saved-searches/${id}`, { method: "DELETE" });
+    } catch {
+        // Ignore
+    }
+    await fetchSavedSearches();
+    showHistory();
+}
+
+// openSavedSearch shows what was indexed since the search was last viewed,
+// falling back to all results when nothing is new.
+async function openSavedSearch(id, all = false) {
+    statusEl.textContent = "Searching...";
+    try {
+        const resp = await fetch(`/api/saved-searches/${id}/results?limit=20${all ? "" : "&new=true"}`);
+        if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+        const data = await resp.json();
+        const s = data.search;
+        if (s.mode === "search") input.value = s.query;
+
+        if (!all && data.total === 0) {
+            openSavedSearch(id, true);
+            return;
+        }
+
+        const what = all ? `${data.total} results` : `${data.total} new since ${data.since ? data.since.slice(0, 10) : "last view"}`;
+        statusEl.textContent = what;
+        const banner = `<div class="similar-banner">
+            <span>Saved search: <strong>${escapeHtml(s.name)}</strong> · ${escapeHtml(what)}</span>
+            ${all ? "" : `<button class="btn-back" onclick="openSavedSearch(${id}, true)">Show all</button>`}
+        </div>`;
+        resultsEl.innerHTML = banner + (data.total > 0 ? renderResultCards(data.results) : '<div class="empty-state">No matching bookmarks found</div>');
+    } catch (err) {
+        statusEl.textContent = `Error: ${err.message}`;
+    }
+}
+
 // --- Utilities ---
 
+// refreshStatus updates the status line unless it is showing search results.
+function refreshStatus() {
+    if (input.value.trim() === "") fetchStatus();
+    else setTimeout(refreshStatus, 5000);
+}
+
 async function fetchStatus() {
     try {
         const resp = await fetch("/api/status");
@@ -236,13 +423,57 @@ async function fetchStatus() {
 
         const parts = [];
         parts.push(`${data.indexCount} bookmarks indexed`);
+        if (data.indexing) parts.push("indexing in progress");
         if (!data.ollamaOk) parts.push("Ollama offline");
+        if (data.sync && data.sync.nextRunAt) {
+            parts.push(`next sync ${new Date(data.sync.nextRunAt).toLocaleString()}`);
+        }
         statusEl.textContent = parts.join(" · ");
+
+        renderUserFilter(data.users || []);
+        renderSourceFilter(data.sources || {});
+
+        // Keep the count current until the background sync finishes
+        if (data.indexing) setTimeout(refreshStatus, 5000);
     } catch {
         // Ignore
     }
 }
 
+function renderUserFilter(users) {
+    networkFilter.classList.toggle("hidden", !users.some((u) => u.network));
+    multiUser = users.length > 1;
+    userFilter.classList.toggle("hidden", !multiUser);
+    userFilter.innerHTML =
+        `<option value="">All users</option>` +
+        users.map((u) => `<option value="${escapeAttr(u.userId)}">User ${escapeHtml(u.userId)} (${u.count})</option>`).join("");
+}
+
+async function fetchClusters() {
+    try {
+        const resp = await fetch("/api/clusters");
+        if (!resp.ok) return;
+        const data = await resp.json();
+        clusterLabels = {};
+        for (const c of data.clusters || []) clusterLabels[c.id] = c.label;
+    } catch {
+        // Ignore
+    }
+}
+
+function searchCluster(id) {
+    input.value = `cluster:${id}`;
+    doSearch(input.value);
+}
+
+function renderSourceFilter(sources) {
+    const names = Object.keys(sources).sort();
+    sourceFilter.classList.toggle("hidden", names.length < 2);
+    sourceFilter.innerHTML =
+        `<option value="">All sources</option>` +
+        names.map((s) => `<option value="${escapeAttr(s)}">${escapeHtml(s)} (${sources[s]})</option>`).join("");
+}
+
 function extractDomain(url) {
     try {
         return new URL(url).hostname;
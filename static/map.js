@@ -0,0 +1,135 @@
+const canvas = document.getElementById("map-canvas");
+const ctx = canvas.getContext("2d");
+const tooltip = document.getElementById("map-tooltip");
+const methodSelect = document.getElementById("map-method");
+const mapStatus = document.getElementById("map-status");
+
+let points = [];
+let clusterLabels = {};
+let view = { scale: 1, x: 0, y: 0 };
+let hovered = null;
+let drag = null;
+
+loadMap();
+
+methodSelect.addEventListener("change", loadMap);
+window.addEventListener("resize", draw);
+
+async function loadMap() {
+    mapStatus.textContent = "Loading...";
+    try {
+        const method = methodSelect.value;
+        const resp = await fetch(`/api/map${method ? `?method=${method}` : ""}`);
+        if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+        const data = await resp.json();
+
+        points = data.points || [];
+        clusterLabels = {};
+        for (const c of data.clusters || []) clusterLabels[c.id] = c.label;
+        view = { scale: 1, x: 0, y: 0 };
+
+        mapStatus.textContent = points.length
+            ? `${points.length} bookmarks · scroll to zoom, drag to pan, click to open`
+            : "No map yet — it is built after the next index run";
+        draw();
+    } catch (err) {
+        mapStatus.textContent = `Error: ${err.message}`;
+    }
+}
+
+// toScreen maps [-1, 1] map coordinates to canvas pixels.
+function toScreen(p) {
+    const size = Math.min(canvas.width, canvas.height) * 0.45;
+    return [
+        canvas.width / 2 + (p.x * size + view.x) * view.scale,
+        canvas.height / 2 + (-p.y * size + view.y) * view.scale,
+    ];
+}
+
+function clusterColor(id) {
+    if (!id) return "#999";
+    return `hsl(${(id * 137.5) % 360}, 65%, 50%)`;
+}
+
+function draw() {
+    canvas.width = window.innerWidth;
+    canvas.height = window.innerHeight - canvas.offsetTop;
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+
+    for (const p of points) {
+        const [sx, sy] = toScreen(p);
+        ctx.fillStyle = clusterColor(p.cluster);
+        ctx.beginPath();
+        ctx.arc(sx, sy, p === hovered ? 6 : 3, 0, Math.PI * 2);
+        ctx.fill();
+    }
+}
+
+function nearestPoint(mx, my) {
+    let best = null;
+    let bestDist = 100; // 10px radius
+    for (const p of points) {
+        const [sx, sy] = toScreen(p);
+        const d = (sx - mx) ** 2 + (sy - my) ** 2;
+        if (d < bestDist) {
+            best = p;
+            bestDist = d;
+        }
+    }
+    return best;
+}
+
+canvas.addEventListener("mousemove", (e) => {
+    const rect = canvas.getBoundingClientRect();
+    const mx = e.clientX - rect.left;
+    const my = e.clientY - rect.top;
+
+    if (drag) {
+        view.x += (mx - drag.x) / view.scale;
+        view.y += (my - drag.y) / view.scale;
+        drag = { x: mx, y: my, moved: true };
+        draw();
+        return;
+    }
+
+    const p = nearestPoint(mx, my);
+    if (p !== hovered) {
+        hovered = p;
+        draw();
+    }
+
+    if (p) {
+        const cluster = clusterLabels[p.cluster] ? `<div class="map-tooltip-cluster">${escapeHtml(clusterLabels[p.cluster])}</div>` : "";
+        const tags = (p.tags || []).map((t) => `<span class="tag-pill">${escapeHtml(t)}</span>`).join(" ");
+        tooltip.innerHTML = `<strong>${escapeHtml(p.title || "Untitled")}</strong>${cluster}${tags}`;
+        tooltip.style.left = `${e.clientX + 12}px`;
+        tooltip.style.top = `${e.clientY + 12}px`;
+        tooltip.classList.remove("hidden");
+    } else {
+        tooltip.classList.add("hidden");
+    }
+});
+
+canvas.addEventListener("mousedown", (e) => {
+    const rect = canvas.getBoundingClientRect();
+    drag = { x: e.clientX - rect.left, y: e.clientY - rect.top, moved: false };
+});
+
+canvas.addEventListener("mouseup", () => {
+    if (drag && !drag.moved && hovered) {
+        window.open(hovered.url, "_blank", "noopener");
+    }
+    drag = null;
+});
+
+canvas.addEventListener("wheel", (e) => {
+    e.preventDefault();
+    view.scale = Math.min(50, Math.max(0.5, view.scale * (e.deltaY < 0 ? 1.2 : 1 / 1.2)));
+    draw();
+}, { passive: false });
+
+function escapeHtml(str) {
+    const div = document.createElement("div");
+    div.textContent = str;
+    return div.innerHTML;
+}